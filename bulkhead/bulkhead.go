@@ -0,0 +1,220 @@
+package bulkhead
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+var CompartmentNotExists = errors.New("Compartment not existed")
+
+// InvalidMaxConcurrent is returned by NewCompartment when maxConcurrent is not positive.
+var InvalidMaxConcurrent = errors.New("max concurrent must be positive")
+
+// InvalidMaxQueue is returned by NewCompartment when maxQueue is negative.
+var InvalidMaxQueue = errors.New("max queue must not be negative")
+
+// RejectedError is returned when a compartment has no free slot and its wait queue is full.
+type RejectedError struct {
+	Compartment   string
+	MaxConcurrent int
+	MaxQueue      int
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("bulkhead %q is full (max concurrent %d, max queue %d)",
+		e.Compartment, e.MaxConcurrent, e.MaxQueue)
+}
+
+// Metrics is a point-in-time view of a compartment.
+type Metrics struct {
+	Name          string
+	MaxConcurrent int
+	MaxQueue      int
+	Active        int64
+	Queued        int64
+	Rejected      uint64
+	Completed     uint64
+}
+
+// Compartment limits how many calls to one dependency run at the same time.
+// Callers beyond the limit wait in a bounded queue and get the free slots in
+// arrival order, they are rejected once the queue is full.
+type Compartment struct {
+	name          string
+	maxConcurrent int
+	maxQueue      int
+	mutex         sync.Mutex
+	// waiters holds a chan struct{} per queued caller, closed when it gets a slot.
+	waiters   list.List
+	active    atomic.Int64
+	queued    atomic.Int64
+	rejected  atomic.Uint64
+	completed atomic.Uint64
+}
+
+// NewCompartment returns a compartment running at most maxConcurrent calls
+// with at most maxQueue callers waiting. It returns InvalidMaxConcurrent or
+// InvalidMaxQueue for a limit out of range.
+func NewCompartment(name string, maxConcurrent int, maxQueue int) (*Compartment, error) {
+	if maxConcurrent < 1 {
+		return nil, InvalidMaxConcurrent
+	}
+	if maxQueue < 0 {
+		return nil, InvalidMaxQueue
+	}
+	return &Compartment{
+		name:          name,
+		maxConcurrent: maxConcurrent,
+		maxQueue:      maxQueue,
+	}, nil
+}
+
+// Acquire takes a slot, waiting in the queue if needed. The returned release
+// function must be called exactly once when the call is finished.
+func (c *Compartment) Acquire(ctx context.Context) (func(), error) {
+	c.mutex.Lock()
+	if c.active.Load() < int64(c.maxConcurrent) && c.waiters.Len() == 0 {
+		c.active.Add(1)
+		c.mutex.Unlock()
+		return c.release(), nil
+	}
+	if c.waiters.Len() >= c.maxQueue {
+		c.mutex.Unlock()
+		c.rejected.Add(1)
+		return nil, &RejectedError{Compartment: c.name, MaxConcurrent: c.maxConcurrent, MaxQueue: c.maxQueue}
+	}
+	ready := make(chan struct{})
+	waiter := c.waiters.PushBack(ready)
+	c.queued.Add(1)
+	c.mutex.Unlock()
+
+	select {
+	case <-ready:
+		return c.release(), nil
+	case <-ctx.Done():
+		c.mutex.Lock()
+		select {
+		case <-ready:
+			// the slot was handed over while ctx was cancelled, pass it on.
+			c.free()
+		default:
+			c.waiters.Remove(waiter)
+			c.queued.Add(-1)
+		}
+		c.mutex.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (c *Compartment) release() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.completed.Add(1)
+			c.mutex.Lock()
+			defer c.mutex.Unlock()
+			c.free()
+		})
+	}
+}
+
+// free hands a slot to the oldest waiter, or gives it back when none is
+// queued. It must be called with the mutex held.
+func (c *Compartment) free() {
+	if front := c.waiters.Front(); front != nil {
+		c.waiters.Remove(front)
+		c.queued.Add(-1)
+		close(front.Value.(chan struct{}))
+		return
+	}
+	c.active.Add(-1)
+}
+
+func (c *Compartment) Name() string {
+	return c.name
+}
+
+func (c *Compartment) Metrics() Metrics {
+	return Metrics{
+		Name:          c.name,
+		MaxConcurrent: c.maxConcurrent,
+		MaxQueue:      c.maxQueue,
+		Active:        c.active.Load(),
+		Queued:        c.queued.Load(),
+		Rejected:      c.rejected.Load(),
+		Completed:     c.completed.Load(),
+	}
+}
+
+// Execute runs fn inside the compartment.
+func Execute[T any](ctx context.Context, c *Compartment, fn func(ctx context.Context) (T, error)) (T, error) {
+	release, err := c.Acquire(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer release()
+	return fn(ctx)
+}
+
+// Bulkhead groups one compartment per downstream dependency.
+type Bulkhead struct {
+	mutex        sync.RWMutex
+	compartments map[string]*Compartment
+}
+
+func New() *Bulkhead {
+	return &Bulkhead{compartments: make(map[string]*Compartment)}
+}
+
+// AddCompartment registers a compartment, replacing any existing one with the
+// same name. It returns the errors of NewCompartment.
+func (b *Bulkhead) AddCompartment(name string, maxConcurrent int, maxQueue int) (*Compartment, error) {
+	compartment, err := NewCompartment(name, maxConcurrent, maxQueue)
+	if err != nil {
+		return nil, err
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.compartments[name] = compartment
+	return compartment, nil
+}
+
+func (b *Bulkhead) Compartment(name string) (*Compartment, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	compartment, ok := b.compartments[name]
+	if !ok {
+		return nil, CompartmentNotExists
+	}
+	return compartment, nil
+}
+
+// Do runs fn inside the named compartment.
+func (b *Bulkhead) Do(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	compartment, err := b.Compartment(name)
+	if err != nil {
+		return err
+	}
+	_, err = Execute(ctx, compartment, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// Metrics returns the metrics of every compartment sorted by name.
+func (b *Bulkhead) Metrics() []Metrics {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	metrics := make([]Metrics, 0, len(b.compartments))
+	for _, compartment := range b.compartments {
+		metrics = append(metrics, compartment.Metrics())
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+	return metrics
+}
@@ -0,0 +1,123 @@
+package bulkhead
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCompartment(t *testing.T) {
+	b := New()
+	if _, err := b.AddCompartment("opensearch", 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	compartment, err := b.Compartment("opensearch")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release, err := compartment.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("first call should get a slot: %v", err)
+	}
+
+	queued := make(chan error)
+	go func() {
+		queued <- b.Do(context.Background(), "opensearch", func(ctx context.Context) error { return nil })
+	}()
+	for compartment.Metrics().Queued != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err = compartment.Acquire(context.Background())
+	var rejected *RejectedError
+	if !errors.As(err, &rejected) || rejected.Compartment != "opensearch" {
+		t.Fatalf("expected RejectedError, got %v", err)
+	}
+
+	release()
+	if err := <-queued; err != nil {
+		t.Fatalf("queued call should run once a slot is free: %v", err)
+	}
+
+	metrics := b.Metrics()
+	expected := Metrics{Name: "opensearch", MaxConcurrent: 1, MaxQueue: 1, Rejected: 1, Completed: 2}
+	if len(metrics) != 1 || metrics[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, metrics)
+	}
+}
+
+func TestCompartmentQueueHonorsContext(t *testing.T) {
+	compartment, _ := NewCompartment("dynamodb", 1, 1)
+	release, _ := compartment.Acquire(context.Background())
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	_, err := Execute(ctx, compartment, func(ctx context.Context) (int, error) { return 1, nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if compartment.Metrics().Queued != 0 {
+		t.Errorf("expected queue to be empty, got %v", compartment.Metrics().Queued)
+	}
+}
+
+func TestCompartmentQueueIsFIFO(t *testing.T) {
+	compartment, _ := NewCompartment("mysql", 1, 3)
+	release, _ := compartment.Acquire(context.Background())
+
+	order := make(chan int, 3)
+	for i := range 3 {
+		go func() {
+			release, err := compartment.Acquire(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			order <- i
+			release()
+		}()
+		for compartment.Metrics().Queued != int64(i+1) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	release()
+	for expected := range 3 {
+		if got := <-order; got != expected {
+			t.Fatalf("expected waiter %d to run next, got %d", expected, got)
+		}
+	}
+	if metrics := compartment.Metrics(); metrics.Active != 0 || metrics.Queued != 0 || metrics.Completed != 4 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+}
+
+func TestNewCompartmentRejectsInvalidLimits(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxConcurrent int
+		maxQueue      int
+		err           error
+	}{
+		{name: "zero concurrent", maxConcurrent: 0, maxQueue: 1, err: InvalidMaxConcurrent},
+		{name: "negative queue", maxConcurrent: 1, maxQueue: -1, err: InvalidMaxQueue},
+		{name: "no queue", maxConcurrent: 1, maxQueue: 0, err: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := New().AddCompartment("redis", test.maxConcurrent, test.maxQueue)
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestUnknownCompartment(t *testing.T) {
+	err := New().Do(context.Background(), "redis", func(ctx context.Context) error { return nil })
+	if !errors.Is(err, CompartmentNotExists) {
+		t.Errorf("expected CompartmentNotExists, got %v", err)
+	}
+}
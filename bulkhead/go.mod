@@ -0,0 +1,3 @@
+module github.com/phuthien0308/ordering-base/bulkhead
+
+go 1.25.5
@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/phuthien0308/ordering-base/simplelog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// LogLevelServiceName exposes the simplelog component levels over gRPC. The
+// messages are well-known types so no generated code is needed on either side:
+//
+//	ListLevels(google.protobuf.Empty) returns (google.protobuf.Struct)  // {"components": [...]}
+//	GetLevel(google.protobuf.Struct) returns (google.protobuf.Struct)   // {"component": "simplelb"}
+//	SetLevel(google.protobuf.Struct) returns (google.protobuf.Struct)   // {"component", "level", "ttl"}
+const LogLevelServiceName = "simplelog.admin.LogLevelService"
+
+type LogLevelServer struct {
+	registry *simplelog.LevelRegistry
+}
+
+func RegisterLogLevelService(s grpc.ServiceRegistrar, registry *simplelog.LevelRegistry) {
+	s.RegisterService(&logLevelServiceDesc, &LogLevelServer{registry: registry})
+}
+
+func (s *LogLevelServer) ListLevels(ctx context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	components := make([]any, 0)
+	for _, level := range s.registry.Components() {
+		components = append(components, levelMap(level))
+	}
+	return structpb.NewStruct(map[string]any{"components": components})
+}
+
+func (s *LogLevelServer) GetLevel(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	level, err := s.registry.Level(req.GetFields()["component"].GetStringValue())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return structpb.NewStruct(levelMap(level))
+}
+
+func (s *LogLevelServer) SetLevel(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	fields := req.GetFields()
+	level, err := s.registry.Apply(simplelog.LevelRequest{
+		Component: fields["component"].GetStringValue(),
+		Level:     fields["level"].GetStringValue(),
+		TTL:       fields["ttl"].GetStringValue(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return structpb.NewStruct(levelMap(level))
+}
+
+func levelMap(level simplelog.ComponentLevel) map[string]any {
+	m := map[string]any{"component": level.Component, "level": level.Level}
+	if !level.ResetAt.IsZero() {
+		m["resetAt"] = level.ResetAt.UTC().Format("2006-01-02T15:04:05Z07:00")
+	}
+	return m
+}
+
+func toStatus(err error) error {
+	if errors.Is(err, simplelog.ComponentNotExists) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+type logLevelService interface {
+	ListLevels(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+	GetLevel(context.Context, *structpb.Struct) (*structpb.Struct, error)
+	SetLevel(context.Context, *structpb.Struct) (*structpb.Struct, error)
+}
+
+var logLevelServiceDesc = grpc.ServiceDesc{
+	ServiceName: LogLevelServiceName,
+	HandlerType: (*logLevelService)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListLevels",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				return handle(srv.(logLevelService).ListLevels, "ListLevels", new(emptypb.Empty), ctx, dec, interceptor)
+			},
+		},
+		{
+			MethodName: "GetLevel",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				return handle(srv.(logLevelService).GetLevel, "GetLevel", new(structpb.Struct), ctx, dec, interceptor)
+			},
+		},
+		{
+			MethodName: "SetLevel",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				return handle(srv.(logLevelService).SetLevel, "SetLevel", new(structpb.Struct), ctx, dec, interceptor)
+			},
+		},
+	},
+	Metadata: "simplelog/admin",
+}
+
+func handle[Req any](method func(context.Context, Req) (*structpb.Struct, error), name string, in Req,
+	ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return method(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/" + LogLevelServiceName + "/" + name}
+	return interceptor(ctx, in, info, func(ctx context.Context, req any) (any, error) {
+		return method(ctx, req.(Req))
+	})
+}
@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/phuthien0308/ordering-base/simplelog"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestLogLevelService(t *testing.T) {
+	logger := simplelog.NewSimpleLogger(zap.NewNop())
+	logger.Named("simplelb")
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	RegisterLogLevelService(server, logger.Levels())
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+
+	list := new(structpb.Struct)
+	if err := conn.Invoke(ctx, "/"+LogLevelServiceName+"/ListLevels", &emptypb.Empty{}, list); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(list.Fields["components"].GetListValue().GetValues()); n != 2 {
+		t.Errorf("expected 2 components, got %v", n)
+	}
+
+	req, _ := structpb.NewStruct(map[string]any{"component": "simplelb", "level": "debug", "ttl": "5m"})
+	resp := new(structpb.Struct)
+	if err := conn.Invoke(ctx, "/"+LogLevelServiceName+"/SetLevel", req, resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Fields["level"].GetStringValue() != "debug" || resp.Fields["resetAt"] == nil {
+		t.Errorf("unexpected response %v", resp)
+	}
+
+	req, _ = structpb.NewStruct(map[string]any{"component": "unknown"})
+	err = conn.Invoke(ctx, "/"+LogLevelServiceName+"/GetLevel", req, resp)
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+}
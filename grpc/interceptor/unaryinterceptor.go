@@ -6,7 +6,6 @@ import (
 
 	"github.com/phuthien0308/ordering-base/simplelog"
 	"github.com/phuthien0308/ordering-base/simplelog/tags"
-	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
@@ -33,22 +32,22 @@ func RequestInterceptor(logger *simplelog.SimpleLogger, env string) grpc.UnarySe
 		if requestID != "" {
 			fields = append(fields, tags.String("request-id", requestID))
 		}
-		logger.Info(ctx, "grpc request", fields...)
-		ctx = context.WithValue(ctx, simplelog.SimpleLogKeyCtx, fields)
+		ctx = simplelog.With(ctx, fields...)
+		logger.Info(ctx, "grpc request")
 		resp, err := handler(ctx, req)
 		duration := time.Since(start)
 		code := status.Convert(err)
-		fields = append(fields, tags.String("grpc.code", code.String()), tags.Duration("duration", duration))
+		result := []tags.T{tags.String("grpc.code", code.String()), tags.Duration("duration", duration)}
 
 		if err == nil {
 			logger.Info(ctx,
 				"grpc request succeeded",
-				append(fields, zap.Error(err))...,
+				result...,
 			)
 		} else {
 			logger.Error(ctx,
 				"grpc request failed",
-				append(fields, zap.Error(err))...,
+				append(result, tags.Err(err))...,
 			)
 		}
 		return resp, err
@@ -0,0 +1,36 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/phuthien0308/ordering-base/simplelog/simplelogtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequestInterceptor(t *testing.T) {
+	logger, recorder := simplelogtest.New(t)
+	recorder.FailOnError()
+	recorder.ExpectError("grpc request failed")
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "r-42"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/product.ProductService/CreateProduct"}
+	_, err := RequestInterceptor(logger, "test")(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+		logger.Info(ctx, "creating product")
+		return nil, status.Error(codes.InvalidArgument, "missing sku")
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected the handler error, got %v", err)
+	}
+
+	recorder.AssertContextField("request-id", "r-42")
+	recorder.AssertContextField("grpc.method", info.FullMethod)
+	recorder.AssertLogged("creating product")
+	failed := recorder.AssertLogged("grpc request failed")
+	if len(failed.HasField("duration")) != 1 {
+		t.Errorf("expected the duration on the final entry, got %v", failed)
+	}
+}
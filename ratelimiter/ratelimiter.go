@@ -15,9 +15,10 @@ type RateLimiter interface {
 }
 
 // NewRedisTokenBucket creates a new Redis-based rate limiter.
+// Allow runs on every request, so its logs are sampled per call site.
 func NewRedisTokenBucket(logger *simplelog.SimpleLogger, clientID string, client *redis.Client) *RedisTokenBucket {
 	ratelimit := &RedisTokenBucket{
-		logger:   logger,
+		logger:   logger.Sampled(10, 100, time.Second),
 		client:   client,
 		ClientID: clientID,
 		clockInSecond: func() float64 {
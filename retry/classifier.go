@@ -0,0 +1,173 @@
+package retry
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Any is retriable when at least one of the classifiers says so.
+func Any(classifiers ...func(error) bool) func(error) bool {
+	return func(err error) bool {
+		for _, classifier := range classifiers {
+			if classifier(err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// All is retriable only when every classifier says so.
+func All(classifiers ...func(error) bool) func(error) bool {
+	return func(err error) bool {
+		for _, classifier := range classifiers {
+			if !classifier(err) {
+				return false
+			}
+		}
+		return len(classifiers) > 0
+	}
+}
+
+// Not is retriable when the classifier is not.
+func Not(classifier func(error) bool) func(error) bool {
+	return func(err error) bool {
+		return !classifier(err)
+	}
+}
+
+// Default combines every classifier of this package and never retries a cancelled context.
+// The gRPC classifier lives in the grpcretry module, keeping gRPC out of this one.
+var Default = All(Not(Canceled), Any(SQL, Redis, HTTP, Network))
+
+// Canceled reports whether the caller gave up, which is never worth retrying.
+func Canceled(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
+
+// Network reports connection resets, refused connections, broken pipes and I/O
+// timeouts. Context errors are excluded: once the caller's deadline passed there
+// is no time left for another attempt.
+func Network(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// SQLSTATE codes worth retrying: serialization failure, deadlock, connection
+// exceptions (class 08) and server shutdown (57P01-57P03).
+var sqlStateRetriable = []string{"40001", "40P01", "08", "57P01", "57P02", "57P03"}
+
+// MySQL error numbers: deadlock, lock wait timeout, server gone away, lost connection.
+var mysqlRetriable = map[int]bool{1213: true, 1205: true, 2006: true, 2013: true}
+
+var mysqlErrorPattern = regexp.MustCompile(`^Error (\d+)`)
+
+// SQL detects broken connections, deadlocks and serialization failures from
+// database/sql and common drivers (pgx, lib/pq via SQLState, go-sql-driver/mysql via the error number).
+func SQL(err error) bool {
+	if err == nil || errors.Is(err, sql.ErrNoRows) || errors.Is(err, sql.ErrTxDone) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		state := stateErr.SQLState()
+		for _, prefix := range sqlStateRetriable {
+			if strings.HasPrefix(state, prefix) {
+				return true
+			}
+		}
+	}
+	if number, found := mysqlNumber(err); found {
+		return mysqlRetriable[number]
+	}
+	return Network(err)
+}
+
+// mysqlNumber finds the first error of the tree, in the errors.As order and
+// including errors.Join branches, whose message starts with a MySQL error number.
+// The driver type is not imported so errors.As can not match it directly.
+func mysqlNumber(err error) (int, bool) {
+	if err == nil {
+		return 0, false
+	}
+	if match := mysqlErrorPattern.FindStringSubmatch(err.Error()); match != nil {
+		number, _ := strconv.Atoi(match[1])
+		return number, true
+	}
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() error }:
+		return mysqlNumber(wrapped.Unwrap())
+	case interface{ Unwrap() []error }:
+		for _, e := range wrapped.Unwrap() {
+			if number, found := mysqlNumber(e); found {
+				return number, true
+			}
+		}
+	}
+	return 0, false
+}
+
+var redisRetriablePrefixes = []string{"LOADING", "READONLY", "CLUSTERDOWN", "TRYAGAIN", "MASTERDOWN", "BUSY"}
+
+// Redis retries failed optimistic transactions, server-side transient states
+// (loading, failover, cluster down) and network errors, never redis.Nil.
+func Redis(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return false
+	}
+	if errors.Is(err, redis.TxFailedErr) {
+		return true
+	}
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		for _, prefix := range redisRetriablePrefixes {
+			if strings.HasPrefix(redisErr.Error(), prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return Network(err)
+}
+
+// HTTPStatus reports whether a response with the given status code should be retried.
+func HTTPStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// HTTP classifies errors exposing the response status through a StatusCode() int method.
+func HTTP(err error) bool {
+	var statusErr interface{ StatusCode() int }
+	if errors.As(err, &statusErr) {
+		return HTTPStatus(statusErr.StatusCode())
+	}
+	return false
+}
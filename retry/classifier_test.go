@@ -0,0 +1,83 @@
+package retry
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type pgError struct{ code string }
+
+func (e *pgError) Error() string    { return "pg error " + e.code }
+func (e *pgError) SQLState() string { return e.code }
+
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+func (redisError) RedisError()     {}
+
+type httpError struct{ code int }
+
+func (e *httpError) Error() string   { return http.StatusText(e.code) }
+func (e *httpError) StatusCode() int { return e.code }
+
+func TestClassifiers(t *testing.T) {
+	tcs := []struct {
+		name       string
+		classifier func(error) bool
+		err        error
+		retriable  bool
+	}{
+		{name: "sql bad conn", classifier: SQL, err: driver.ErrBadConn, retriable: true},
+		{name: "sql no rows", classifier: SQL, err: sql.ErrNoRows, retriable: false},
+		{name: "postgres serialization failure", classifier: SQL, err: &pgError{"40001"}, retriable: true},
+		{name: "postgres deadlock", classifier: SQL, err: fmt.Errorf("tx: %w", &pgError{"40P01"}), retriable: true},
+		{name: "postgres unique violation", classifier: SQL, err: &pgError{"23505"}, retriable: false},
+		{name: "mysql deadlock", classifier: SQL, err: errors.New("Error 1213 (40001): Deadlock found"), retriable: true},
+		{name: "mysql duplicate entry", classifier: SQL, err: errors.New("Error 1062 (23000): Duplicate entry"), retriable: false},
+		{name: "mysql joined lock wait timeout", classifier: SQL, err: errors.Join(errors.New("rollback failed"), fmt.Errorf("update: %w", errors.New("Error 1205 (HY000): Lock wait timeout exceeded"))), retriable: true},
+		{name: "sql connection reset", classifier: SQL, err: fmt.Errorf("read: %w", syscall.ECONNRESET), retriable: true},
+		{name: "redis nil", classifier: Redis, err: redis.Nil, retriable: false},
+		{name: "redis tx failed", classifier: Redis, err: redis.TxFailedErr, retriable: true},
+		{name: "redis loading", classifier: Redis, err: redisError("LOADING Redis is loading the dataset"), retriable: true},
+		{name: "redis wrong type", classifier: Redis, err: redisError("WRONGTYPE Operation"), retriable: false},
+		{name: "http service unavailable", classifier: HTTP, err: &httpError{http.StatusServiceUnavailable}, retriable: true},
+		{name: "http bad request", classifier: HTTP, err: &httpError{http.StatusBadRequest}, retriable: false},
+		{name: "network io timeout", classifier: Network, err: &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, retriable: true},
+		{name: "network context deadline", classifier: Network, err: fmt.Errorf("dial: %w", context.DeadlineExceeded), retriable: false},
+		{name: "default cancelled", classifier: Default, err: fmt.Errorf("%w: %w", context.Canceled, syscall.ECONNRESET), retriable: false},
+		{name: "default sql", classifier: Default, err: driver.ErrBadConn, retriable: true},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.classifier(tc.err); got != tc.retriable {
+				t.Errorf("expected retriable %v, got %v", tc.retriable, got)
+			}
+		})
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	yes := func(error) bool { return true }
+	no := func(error) bool { return false }
+	err := errors.New("any")
+	if !Any(no, yes)(err) || Any(no, no)(err) || Any()(err) {
+		t.Error("unexpected Any result")
+	}
+	if !All(yes, yes)(err) || All(yes, no)(err) || All()(err) {
+		t.Error("unexpected All result")
+	}
+	if Not(yes)(err) || !Not(no)(err) {
+		t.Error("unexpected Not result")
+	}
+}
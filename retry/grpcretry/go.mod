@@ -0,0 +1,28 @@
+module github.com/phuthien0308/ordering-base/retry/grpcretry
+
+go 1.25.5
+
+replace (
+	github.com/phuthien0308/ordering-base/retry => ../
+	github.com/phuthien0308/ordering-base/simplelog => ../../simplelog
+)
+
+require (
+	github.com/phuthien0308/ordering-base/retry v0.0.0-00010101000000-000000000000
+	google.golang.org/grpc v1.81.1
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/go-redis/redis/v8 v8.11.5 // indirect
+	github.com/phuthien0308/ordering-base/simplelog v0.0.0-00010101000000-000000000000 // indirect
+	go.opentelemetry.io/otel v1.44.0 // indirect
+	go.opentelemetry.io/otel/metric v1.44.0 // indirect
+	go.opentelemetry.io/otel/trace v1.44.0 // indirect
+	go.uber.org/multierr v1.11.0 // indirect
+	go.uber.org/zap v1.27.1 // indirect
+	golang.org/x/sys v0.45.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260226221140-a57be14db171 // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
+)
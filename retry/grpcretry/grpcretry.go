@@ -0,0 +1,35 @@
+// Package grpcretry classifies gRPC errors for the retry package. It is a module
+// of its own so retry does not depend on gRPC.
+package grpcretry
+
+import (
+	"github.com/phuthien0308/ordering-base/retry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeadlineExceeded is left out like in retry.Network: the caller's deadline
+// covers every attempt, so none is left for another one.
+var retriableCodes = []codes.Code{codes.Unavailable, codes.ResourceExhausted, codes.Aborted}
+
+// GRPC retries Unavailable, ResourceExhausted and Aborted.
+var GRPC = Codes(retriableCodes...)
+
+// Default is retry.Default plus GRPC, it never retries a cancelled context.
+var Default = retry.All(retry.Not(retry.Canceled), retry.Any(GRPC, retry.Default))
+
+// Codes is retriable when the error carries one of the given gRPC status codes.
+func Codes(retriable ...codes.Code) func(error) bool {
+	return func(err error) bool {
+		st, ok := status.FromError(err)
+		if !ok || st == nil {
+			return false
+		}
+		for _, code := range retriable {
+			if st.Code() == code {
+				return true
+			}
+		}
+		return false
+	}
+}
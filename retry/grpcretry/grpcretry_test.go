@@ -0,0 +1,39 @@
+package grpcretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClassifiers(t *testing.T) {
+	tcs := []struct {
+		name       string
+		classifier func(error) bool
+		err        error
+		retriable  bool
+	}{
+		{name: "grpc unavailable", classifier: GRPC, err: status.Error(codes.Unavailable, "down"), retriable: true},
+		{name: "grpc wrapped aborted", classifier: GRPC, err: fmt.Errorf("call: %w", status.Error(codes.Aborted, "")), retriable: true},
+		{name: "grpc invalid argument", classifier: GRPC, err: status.Error(codes.InvalidArgument, "bad sku"), retriable: false},
+		{name: "grpc deadline exceeded", classifier: GRPC, err: status.Error(codes.DeadlineExceeded, ""), retriable: false},
+		{name: "grpc plain error", classifier: GRPC, err: errors.New("boom"), retriable: false},
+		{name: "custom codes", classifier: Codes(codes.DeadlineExceeded), err: status.Error(codes.DeadlineExceeded, ""), retriable: true},
+		{name: "default grpc", classifier: Default, err: status.Error(codes.ResourceExhausted, ""), retriable: true},
+		{name: "default network", classifier: Default, err: fmt.Errorf("read: %w", syscall.ECONNRESET), retriable: true},
+		{name: "default cancelled", classifier: Default, err: fmt.Errorf("%w: %w", context.Canceled, status.Error(codes.Unavailable, "")), retriable: false},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.classifier(tc.err); got != tc.retriable {
+				t.Errorf("expected retriable %v, got %v", tc.retriable, got)
+			}
+		})
+	}
+}
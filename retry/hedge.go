@@ -0,0 +1,182 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HedgeDelay decides how long Hedge waits before launching the next attempt.
+// Observe is called, possibly concurrently, with the latency of the successful
+// attempts and of the attempts cancelled after losing, so adaptive
+// implementations can follow the real latency distribution.
+type HedgeDelay interface {
+	Delay() time.Duration
+	Observe(latency time.Duration)
+}
+
+// FixedDelay always waits the same duration before hedging.
+type FixedDelay time.Duration
+
+func (d FixedDelay) Delay() time.Duration { return time.Duration(d) }
+
+func (d FixedDelay) Observe(time.Duration) {}
+
+// PercentileDelay hedges once an attempt is slower than the given percentile
+// of the last observed latencies. Until enough samples are collected the
+// fallback delay is used.
+type PercentileDelay struct {
+	mutex      sync.Mutex
+	percentile float64
+	fallback   time.Duration
+	minSamples int
+	samples    []time.Duration
+	next       int
+}
+
+// NewPercentileDelay keeps a ring of the last window latencies, percentile is in (0, 1].
+func NewPercentileDelay(percentile float64, window int, fallback time.Duration) *PercentileDelay {
+	if window <= 0 {
+		window = 100
+	}
+	if percentile <= 0 || percentile > 1 {
+		percentile = 0.95
+	}
+	return &PercentileDelay{
+		percentile: percentile,
+		fallback:   fallback,
+		minSamples: min(10, window),
+		samples:    make([]time.Duration, 0, window),
+	}
+}
+
+func (p *PercentileDelay) Delay() time.Duration {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if len(p.samples) < p.minSamples {
+		return p.fallback
+	}
+	sorted := make([]time.Duration, len(p.samples))
+	copy(sorted, p.samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := int(float64(len(sorted))*p.percentile+0.5) - 1
+	index = max(0, min(index, len(sorted)-1))
+	return sorted[index]
+}
+
+func (p *PercentileDelay) Observe(latency time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if len(p.samples) < cap(p.samples) {
+		p.samples = append(p.samples, latency)
+		return
+	}
+	p.samples[p.next] = latency
+	p.next = (p.next + 1) % len(p.samples)
+}
+
+// InvalidMaxHedges is returned by Hedge when maxHedges is negative.
+var InvalidMaxHedges = errors.New("max hedges must not be negative")
+
+// InvalidMaxAttempts is returned by Hedge when WithMaxAttempts is not positive.
+var InvalidMaxAttempts = errors.New("max attempts must be positive")
+
+// errHedgeLost cancels the attempts still running once another one won.
+var errHedgeLost = errors.New("another hedged attempt won")
+
+type hedgeOptions struct {
+	maxAttempts int
+}
+
+// HedgeOption customizes Hedge.
+type HedgeOption func(*hedgeOptions)
+
+// WithMaxAttempts bounds the attempts launched in total, maxHedges+1 by default.
+func WithMaxAttempts(n int) HedgeOption {
+	return func(o *hedgeOptions) {
+		o.maxAttempts = n
+	}
+}
+
+type hedgeResult[T any] struct {
+	attempt int
+	value   T
+	err     error
+}
+
+// Hedge calls fn once and, every time the hedging delay passes without a
+// successful answer, launches another attempt as long as at most maxHedges
+// extra attempts are in flight at once and WithMaxAttempts is not reached.
+// A failed attempt frees its slot and triggers the next hedge immediately.
+// The first success wins: its value and attempt number (0 for the original
+// call) are returned and the contexts of all other attempts are cancelled.
+// When every attempt fails the errors are joined together.
+//
+// The delay observes the latency of every attempt that succeeds, winner or not,
+// and of the attempts cancelled because another one won, which were at least that slow.
+func Hedge[T any](ctx context.Context, fn func(ctx context.Context) (T, error), delay HedgeDelay,
+	maxHedges int, opts ...HedgeOption) (T, int, error) {
+	var zero T
+	if maxHedges < 0 {
+		return zero, -1, InvalidMaxHedges
+	}
+	options := hedgeOptions{maxAttempts: maxHedges + 1}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.maxAttempts <= 0 {
+		return zero, -1, InvalidMaxAttempts
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(errHedgeLost)
+
+	// buffered so attempts that lose the race never block after we return.
+	results := make(chan hedgeResult[T], options.maxAttempts)
+	launch := func(attempt int) {
+		go func() {
+			start := time.Now()
+			value, err := fn(ctx)
+			if err == nil || errors.Is(context.Cause(ctx), errHedgeLost) {
+				delay.Observe(time.Since(start))
+			}
+			results <- hedgeResult[T]{attempt: attempt, value: value, err: err}
+		}()
+	}
+
+	launched, inFlight := 1, 1
+	launch(0)
+	timer := time.NewTimer(delay.Delay())
+	defer timer.Stop()
+
+	var errs []error
+	for inFlight > 0 {
+		select {
+		case <-ctx.Done():
+			return zero, -1, errors.Join(append(errs, ctx.Err())...)
+		case <-timer.C:
+			if launched < options.maxAttempts {
+				if inFlight <= maxHedges {
+					launch(launched)
+					launched++
+					inFlight++
+				}
+				timer.Reset(delay.Delay())
+			}
+		case result := <-results:
+			inFlight--
+			if result.err == nil {
+				return result.value, result.attempt, nil
+			}
+			errs = append(errs, result.err)
+			if launched < options.maxAttempts {
+				launch(launched)
+				launched++
+				inFlight++
+				timer.Reset(delay.Delay())
+			}
+		}
+	}
+	return zero, -1, errors.Join(errs...)
+}
@@ -0,0 +1,147 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedge(t *testing.T) {
+	t.Run("primary wins before hedging", func(t *testing.T) {
+		var calls atomic.Int32
+		result, winner, err := Hedge(context.Background(), func(ctx context.Context) (int, error) {
+			calls.Add(1)
+			return 42, nil
+		}, FixedDelay(50*time.Millisecond), 2)
+		if err != nil || result != 42 || winner != 0 {
+			t.Fatalf("got result %v winner %v err %v", result, winner, err)
+		}
+		if calls.Load() != 1 {
+			t.Errorf("expected a single call, got %v", calls.Load())
+		}
+	})
+
+	t.Run("hedge wins and slow attempt is cancelled", func(t *testing.T) {
+		var calls atomic.Int32
+		cancelled := make(chan struct{})
+		result, winner, err := Hedge(context.Background(), func(ctx context.Context) (int, error) {
+			if calls.Add(1) == 1 {
+				<-ctx.Done()
+				close(cancelled)
+				return 0, ctx.Err()
+			}
+			return 7, nil
+		}, FixedDelay(time.Millisecond), 1)
+		if err != nil || result != 7 || winner != 1 {
+			t.Fatalf("got result %v winner %v err %v", result, winner, err)
+		}
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Error("slow attempt was not cancelled")
+		}
+	})
+
+	t.Run("respects max hedges and joins errors", func(t *testing.T) {
+		var calls atomic.Int32
+		_, winner, err := Hedge(context.Background(), func(ctx context.Context) (int, error) {
+			calls.Add(1)
+			return 0, errors.New("unavailable")
+		}, FixedDelay(time.Millisecond), 2)
+		if err == nil || winner != -1 {
+			t.Fatalf("expected failure, got winner %v err %v", winner, err)
+		}
+		if calls.Load() != 3 {
+			t.Errorf("expected 3 attempts, got %v", calls.Load())
+		}
+	})
+}
+
+func TestHedgeConcurrency(t *testing.T) {
+	var inFlight, peak, calls atomic.Int32
+	_, winner, err := Hedge(context.Background(), func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return 0, errors.New("unavailable")
+	}, FixedDelay(time.Millisecond), 1, WithMaxAttempts(6))
+	if err == nil || winner != -1 {
+		t.Fatalf("expected failure, got winner %v err %v", winner, err)
+	}
+	if calls.Load() != 6 || peak.Load() > 2 {
+		t.Errorf("expected 6 attempts with at most 2 in flight, got %v attempts and %v in flight", calls.Load(), peak.Load())
+	}
+}
+
+func TestHedgeInvalidArguments(t *testing.T) {
+	fn := func(ctx context.Context) (int, error) { return 1, nil }
+	if _, _, err := Hedge(context.Background(), fn, FixedDelay(time.Millisecond), -2); !errors.Is(err, InvalidMaxHedges) {
+		t.Errorf("expected InvalidMaxHedges, got %v", err)
+	}
+	if _, _, err := Hedge(context.Background(), fn, FixedDelay(time.Millisecond), 1, WithMaxAttempts(0)); !errors.Is(err, InvalidMaxAttempts) {
+		t.Errorf("expected InvalidMaxAttempts, got %v", err)
+	}
+}
+
+type recordingDelay struct {
+	FixedDelay
+	observed chan time.Duration
+}
+
+func (d recordingDelay) Observe(latency time.Duration) { d.observed <- latency }
+
+func TestHedgeObservesLosers(t *testing.T) {
+	delay := recordingDelay{FixedDelay: FixedDelay(10 * time.Millisecond), observed: make(chan time.Duration, 2)}
+	var calls atomic.Int32
+	_, winner, err := Hedge(context.Background(), func(ctx context.Context) (int, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return 7, nil
+	}, delay, 1)
+	if err != nil || winner != 1 {
+		t.Fatalf("got winner %v err %v", winner, err)
+	}
+	var slowest time.Duration
+	for range 2 {
+		select {
+		case latency := <-delay.observed:
+			slowest = max(slowest, latency)
+		case <-time.After(time.Second):
+			t.Fatal("expected the winner and the cancelled loser to be observed")
+		}
+	}
+	if slowest < 10*time.Millisecond {
+		t.Errorf("expected the loser to be observed after the hedging delay, got %v", slowest)
+	}
+}
+
+func TestPercentileDelay(t *testing.T) {
+	delay := NewPercentileDelay(0.9, 10, time.Second)
+	if delay.Delay() != time.Second {
+		t.Fatalf("expected fallback before enough samples, got %v", delay.Delay())
+	}
+	for i := 1; i <= 10; i++ {
+		delay.Observe(time.Duration(i) * time.Millisecond)
+	}
+	if got := delay.Delay(); got != 9*time.Millisecond {
+		t.Errorf("expected p90 of 9ms, got %v", got)
+	}
+	// the ring overwrites the oldest samples.
+	for i := 0; i < 10; i++ {
+		delay.Observe(100 * time.Millisecond)
+	}
+	if got := delay.Delay(); got != 100*time.Millisecond {
+		t.Errorf("expected 100ms after window rolled, got %v", got)
+	}
+}
@@ -0,0 +1,82 @@
+package retry
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Metrics holds the retry instruments: retry.attempts counts the finished attempts
+// by operation and outcome, retry.give_ups the operations given up and
+// retry.delay the waits before the next attempt, in milliseconds.
+type Metrics struct {
+	attempts metric.Int64Counter
+	giveUps  metric.Int64Counter
+	delay    metric.Float64Histogram
+}
+
+// NewMetrics creates the instruments on meter, e.g. otel.Meter("retry").
+func NewMetrics(meter metric.Meter) (*Metrics, error) {
+	attempts, err := meter.Int64Counter("retry.attempts", metric.WithUnit("{attempt}"),
+		metric.WithDescription("Number of attempts made."))
+	if err != nil {
+		return nil, err
+	}
+	giveUps, err := meter.Int64Counter("retry.give_ups", metric.WithUnit("{operation}"),
+		metric.WithDescription("Number of operations given up."))
+	if err != nil {
+		return nil, err
+	}
+	delay, err := meter.Float64Histogram("retry.delay", metric.WithUnit("ms"),
+		metric.WithDescription("Wait before the next attempt."))
+	if err != nil {
+		return nil, err
+	}
+	return &Metrics{attempts: attempts, giveUps: giveUps, delay: delay}, nil
+}
+
+// observeAttempt records a finished attempt on the active span and the counters.
+// delay is the wait before the next attempt, zero when there is none.
+func (o options) observeAttempt(ctx context.Context, attempt int, delay time.Duration, err error) {
+	if o.metrics != nil {
+		outcome := "success"
+		if err != nil {
+			outcome = "error"
+		}
+		o.metrics.attempts.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("retry.operation", o.operation), attribute.String("retry.outcome", outcome)))
+		if delay > 0 {
+			o.metrics.delay.Record(ctx, float64(delay)/float64(time.Millisecond),
+				metric.WithAttributes(attribute.String("retry.operation", o.operation)))
+		}
+	}
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		attrs := []attribute.KeyValue{
+			attribute.String("retry.operation", o.operation),
+			attribute.Int("retry.attempt", attempt),
+			attribute.Int64("retry.delay_ms", delay.Milliseconds()),
+		}
+		if err != nil {
+			attrs = append(attrs, attribute.String("retry.error", err.Error()))
+		}
+		span.AddEvent("retry.attempt", trace.WithAttributes(attrs...))
+	}
+}
+
+func (o options) retrying(ctx context.Context, attempt int, delay time.Duration, err error) {
+	for _, fn := range o.onRetry {
+		fn(ctx, attempt, delay, err)
+	}
+}
+
+func (o options) givingUp(ctx context.Context, attempts int, err error) {
+	if o.metrics != nil {
+		o.metrics.giveUps.Add(ctx, 1, metric.WithAttributes(attribute.String("retry.operation", o.operation)))
+	}
+	for _, fn := range o.onGiveUp {
+		fn(ctx, attempts, err)
+	}
+}
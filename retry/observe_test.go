@@ -0,0 +1,96 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/phuthien0308/ordering-base/simplelog"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRetryObservability(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("retry").Start(context.Background(), "search")
+
+	core, logs := observer.New(zap.InfoLevel)
+	reader := sdkmetric.NewManualReader()
+	metrics, err := NewMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("retry"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var retried []int
+	var gaveUp int
+
+	_, err = Retry(ctx, func() (int, error) {
+		return 0, errors.New("unavailable")
+	}, func(error) bool { return true }, 3, time.Millisecond,
+		WithOperation("SearchProducts"),
+		WithMetrics(metrics),
+		WithLogger(simplelog.NewSimpleLogger(zap.New(core))),
+		WithOnRetry(func(ctx context.Context, attempt int, delay time.Duration, err error) {
+			retried = append(retried, attempt)
+		}),
+		WithOnGiveUp(func(ctx context.Context, attempts int, err error) {
+			gaveUp = attempts
+		}),
+	)
+	span.End()
+
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(retried) != 2 || gaveUp != 3 {
+		t.Errorf("unexpected callbacks, retried %v gave up after %v", retried, gaveUp)
+	}
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatal(err)
+	}
+	totals := make(map[string]int64)
+	for _, m := range data.ScopeMetrics[0].Metrics {
+		switch values := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			for _, point := range values.DataPoints {
+				totals[m.Name] += point.Value
+				if operation, _ := point.Attributes.Value("retry.operation"); operation.AsString() != "SearchProducts" {
+					t.Errorf("unexpected attributes %v", point.Attributes.ToSlice())
+				}
+			}
+		case metricdata.Histogram[float64]:
+			for _, point := range values.DataPoints {
+				totals[m.Name] += int64(point.Count)
+			}
+		}
+	}
+	if totals["retry.attempts"] != 3 || totals["retry.give_ups"] != 1 || totals["retry.delay"] != 2 {
+		t.Errorf("unexpected totals %v", totals)
+	}
+	if logs.FilterMessage("retrying operation").Len() != 2 || logs.FilterMessage("giving up operation").Len() != 1 {
+		t.Errorf("unexpected logs %v", logs.All())
+	}
+
+	events := recorder.Ended()[0].Events()
+	if len(events) != 3 {
+		t.Fatalf("expected 3 span events, got %v", len(events))
+	}
+	for i, event := range events {
+		found := false
+		for _, attr := range event.Attributes {
+			if attr == attribute.Int("retry.attempt", i+1) {
+				found = true
+			}
+		}
+		if event.Name != "retry.attempt" || !found {
+			t.Errorf("unexpected event %v", event)
+		}
+	}
+}
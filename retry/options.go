@@ -0,0 +1,80 @@
+package retry
+
+import (
+	"context"
+	"time"
+
+	"github.com/phuthien0308/ordering-base/simplelog"
+	"github.com/phuthien0308/ordering-base/simplelog/tags"
+)
+
+// Option customizes Retry and RetryContext.
+type Option func(*options)
+
+type options struct {
+	attemptTimeout time.Duration
+	operation      string
+	onRetry        []func(ctx context.Context, attempt int, delay time.Duration, err error)
+	onGiveUp       []func(ctx context.Context, attempts int, err error)
+	metrics        *Metrics
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithAttemptTimeout bounds every single attempt, the overall deadline still
+// comes from the parent context. Zero means no per-attempt timeout.
+func WithAttemptTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.attemptTimeout = timeout
+	}
+}
+
+// WithOperation names the retried operation in span events, counters and logs.
+func WithOperation(name string) Option {
+	return func(o *options) {
+		o.operation = name
+	}
+}
+
+// WithOnRetry registers a callback invoked after a failed attempt, before waiting delay for the next one.
+func WithOnRetry(fn func(ctx context.Context, attempt int, delay time.Duration, err error)) Option {
+	return func(o *options) {
+		o.onRetry = append(o.onRetry, fn)
+	}
+}
+
+// WithOnGiveUp registers a callback invoked once no more attempts will be made
+// because the budget is spent, the error is not retriable or the context is done.
+func WithOnGiveUp(fn func(ctx context.Context, attempts int, err error)) Option {
+	return func(o *options) {
+		o.onGiveUp = append(o.onGiveUp, fn)
+	}
+}
+
+// WithMetrics records the attempts, give-ups and delays under the operation name.
+func WithMetrics(metrics *Metrics) Option {
+	return func(o *options) {
+		o.metrics = metrics
+	}
+}
+
+// WithLogger logs every retry as a warning and every give-up as an error.
+func WithLogger(logger *simplelog.SimpleLogger) Option {
+	return func(o *options) {
+		operation := func() tags.T { return tags.String("retry.operation", o.operation) }
+		o.onRetry = append(o.onRetry, func(ctx context.Context, attempt int, delay time.Duration, err error) {
+			logger.Warn(ctx, "retrying operation", operation(), tags.Int("retry.attempt", attempt),
+				tags.Duration("retry.delay", delay), tags.Error(err))
+		})
+		o.onGiveUp = append(o.onGiveUp, func(ctx context.Context, attempts int, err error) {
+			logger.Error(ctx, "giving up operation", operation(), tags.Int("retry.attempts", attempts),
+				tags.Error(err))
+		})
+	}
+}
@@ -2,34 +2,97 @@ package retry
 
 import (
 	"context"
+	"errors"
 	"math/rand"
 
 	"time"
 )
 
+// InvalidAttempt is returned when the number of attempts is not positive, fn is never called.
+var InvalidAttempt = errors.New("attempt must be positive")
+
+type attemptKey struct{}
+
+// Attempt returns the 1-based attempt number stored by RetryContext,
+// or 0 when ctx does not come from a retry.
+func Attempt(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
+
 func Retry[T any](ctx context.Context, fn func() (T, error), retriable func(error) bool, attempt int,
-	initalDurationInMs time.Duration) (T, error) {
+	initalDurationInMs time.Duration, opts ...Option) (T, error) {
+	result, errs, ctxErr := run(ctx, func(context.Context) (T, error) { return fn() }, retriable, attempt,
+		initalDurationInMs, newOptions(opts))
+	if ctxErr != nil {
+		return result, ctxErr
+	}
+	if len(errs) == 0 {
+		return result, nil
+	}
+	return result, errs[len(errs)-1]
+}
+
+// RetryContext is like Retry but gives every attempt its own context, bounded by
+// WithAttemptTimeout and carrying the attempt number (see Attempt).
+// When all attempts fail, every attempt's error is returned joined with errors.Join.
+func RetryContext[T any](ctx context.Context, fn func(ctx context.Context) (T, error), retriable func(error) bool,
+	attempt int, initalDurationInMs time.Duration, opts ...Option) (T, error) {
+	result, errs, ctxErr := run(ctx, fn, retriable, attempt, initalDurationInMs, newOptions(opts))
+	if ctxErr != nil {
+		errs = append(errs, ctxErr)
+	}
+	return result, errors.Join(errs...)
+}
+
+// run returns the last result, the errors of the failed attempts (empty on success)
+// and InvalidAttempt or the context error if ctx was done while waiting for the next attempt.
+func run[T any](ctx context.Context, fn func(ctx context.Context) (T, error), retriable func(error) bool,
+	attempt int, initialDuration time.Duration, o options) (T, []error, error) {
 	var result T
+	if attempt <= 0 {
+		return result, nil, InvalidAttempt
+	}
 	var err error
+	var errs []error
 	timer := time.NewTimer(0)
+	defer timer.Stop()
 	for i := 0; i < attempt; i++ {
-		result, err = fn()
+		result, err = callAttempt(ctx, fn, i+1, o)
 		if err == nil {
-			break
+			o.observeAttempt(ctx, i+1, 0, nil)
+			return result, nil, nil
 		}
-		if !retriable(err) {
+		errs = append(errs, err)
+		if !retriable(err) || i == attempt-1 {
+			o.observeAttempt(ctx, i+1, 0, err)
 			break
 		}
-		waiting := waitingTime(i, initalDurationInMs)
+		waiting := waitingTime(i, initialDuration)
+		o.observeAttempt(ctx, i+1, waiting, err)
+		o.retrying(ctx, i+1, waiting, err)
 		timer.Reset(waiting)
 		select {
 		case <-ctx.Done():
-			return result, ctx.Err()
+			o.givingUp(ctx, i+1, ctx.Err())
+			return result, errs, ctx.Err()
 		case <-timer.C:
 			continue
 		}
 	}
-	return result, err
+	o.givingUp(ctx, len(errs), err)
+	return result, errs, nil
+}
+
+func callAttempt[T any](ctx context.Context, fn func(ctx context.Context) (T, error), attempt int,
+	o options) (T, error) {
+	ctx = context.WithValue(ctx, attemptKey{}, attempt)
+	if o.attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.attemptTimeout)
+		defer cancel()
+	}
+	return fn(ctx)
 }
 
 func waitingTime(attempt int, initialDuration time.Duration) time.Duration {
@@ -131,3 +131,64 @@ func TestRetry(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryContext(t *testing.T) {
+	t.Run("attempt number and timeout", func(t *testing.T) {
+		var seen []int
+		result, err := RetryContext(context.Background(), func(ctx context.Context) (int, error) {
+			seen = append(seen, Attempt(ctx))
+			if Attempt(ctx) < 3 {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			}
+			return 42, nil
+		}, func(error) bool { return true }, 3, time.Millisecond, WithAttemptTimeout(5*time.Millisecond))
+		if err != nil || result != 42 {
+			t.Fatalf("expected 42 without error, got %v %v", result, err)
+		}
+		if len(seen) != 3 || seen[0] != 1 || seen[2] != 3 {
+			t.Errorf("unexpected attempt numbers %v", seen)
+		}
+	})
+
+	t.Run("joins every attempt error", func(t *testing.T) {
+		first, second := errors.New("first"), errors.New("second")
+		errs := []error{first, second}
+		_, err := RetryContext(context.Background(), func(ctx context.Context) (int, error) {
+			return 0, errs[Attempt(ctx)-1]
+		}, func(error) bool { return true }, 2, time.Millisecond)
+		if !errors.Is(err, first) || !errors.Is(err, second) {
+			t.Errorf("expected both errors, got %v", err)
+		}
+	})
+
+	t.Run("stops on non retriable error", func(t *testing.T) {
+		calls := 0
+		_, err := RetryContext(context.Background(), func(ctx context.Context) (int, error) {
+			calls++
+			return 0, errors.New("invalid")
+		}, func(error) bool { return false }, 3, time.Millisecond)
+		if err == nil || calls != 1 {
+			t.Errorf("expected a single failed call, got %v calls, err %v", calls, err)
+		}
+	})
+}
+
+func TestRetryInvalidAttempt(t *testing.T) {
+	called := false
+	fn := func(context.Context) (int, error) {
+		called = true
+		return 42, nil
+	}
+	for _, attempt := range []int{0, -1} {
+		if _, err := RetryContext(context.Background(), fn, func(error) bool { return true }, attempt, time.Millisecond); !errors.Is(err, InvalidAttempt) {
+			t.Errorf("attempt %d: expected InvalidAttempt, got %v", attempt, err)
+		}
+		if _, err := Retry(context.Background(), func() (int, error) { return fn(context.Background()) }, func(error) bool { return true }, attempt, time.Millisecond); !errors.Is(err, InvalidAttempt) {
+			t.Errorf("attempt %d: expected InvalidAttempt, got %v", attempt, err)
+		}
+	}
+	if called {
+		t.Error("fn must not be called")
+	}
+}
@@ -0,0 +1,325 @@
+// Package audit records who changed what in a dedicated, append-only sink kept
+// apart from operational logs. Every entry embeds the hash of the previous one,
+// so editing or removing a line breaks the chain and is detected by Verify.
+//
+// Without WithKey the chain is a plain sha256, it detects corruption and
+// accidental edits only: anyone able to write the sink can recompute it. Pass
+// a key kept outside the sink to make it an HMAC that can not be forged.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/phuthien0308/ordering-base/simplelog"
+)
+
+// RequestIDKey is the simplelog context field used to fill Event.RequestID.
+const RequestIDKey = "request-id"
+
+var InvalidEvent = errors.New("Actor, action, resource type and resource id are required")
+
+type Change struct {
+	Field  string `json:"field"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+type Event struct {
+	Time         time.Time `json:"time"`
+	Actor        string    `json:"actor"`
+	Action       string    `json:"action"`
+	ResourceType string    `json:"resourceType"`
+	ResourceID   string    `json:"resourceId"`
+	Changes      []Change  `json:"changes,omitempty"`
+	RequestID    string    `json:"requestId,omitempty"`
+}
+
+// Entry is an Event at its position in the chain.
+type Entry struct {
+	Sequence uint64 `json:"seq"`
+	PrevHash string `json:"prevHash"`
+	Event
+}
+
+// Record is one line of the audit sink. Hash covers the exact bytes of Entry.
+type Record struct {
+	Hash  string          `json:"hash"`
+	Entry json.RawMessage `json:"entry"`
+}
+
+// Option configures the chain of a Logger or of Verify.
+type Option func(*options)
+
+type options struct {
+	key []byte
+}
+
+// WithKey chains the entries with an HMAC-SHA256 keyed by key instead of a
+// plain sha256. The same key must be given to Verify and OpenFile.
+func WithKey(key []byte) Option {
+	return func(o *options) {
+		o.key = key
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Logger appends audit entries to its sink.
+type Logger struct {
+	mutex    sync.Mutex
+	writer   io.Writer
+	closer   io.Closer
+	key      []byte
+	sequence uint64
+	lastHash string
+	now      func() time.Time
+	// offset is the size of the complete records, truncate cuts a partial
+	// record after a failed write. Without truncate the logger is broken.
+	offset   int64
+	truncate func(size int64) error
+	broken   error
+}
+
+// NewLogger starts a new chain on w.
+func NewLogger(w io.Writer, opts ...Option) *Logger {
+	return &Logger{writer: w, key: newOptions(opts).key, now: time.Now}
+}
+
+// OpenFile appends to the audit file at path, continuing the chain already in it.
+// The existing content is verified first so a tampered file is never extended.
+// A partial last record, left by a crash in the middle of a write, is cut.
+func OpenFile(path string, opts ...Option) (*Logger, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	key := newOptions(opts).key
+	last, err := verify(file, key)
+	var partial *PartialRecordError
+	if errors.As(err, &partial) {
+		err = file.Truncate(partial.Offset)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat audit file: %w", err)
+	}
+	logger := &Logger{writer: file, closer: file, key: key, now: time.Now, offset: info.Size(), truncate: file.Truncate}
+	if last != nil {
+		logger.sequence, logger.lastHash = last.Sequence, last.hash
+	}
+	return logger, nil
+}
+
+// Log validates the event, completes its time and request id, and appends it.
+func (l *Logger) Log(ctx context.Context, event Event) error {
+	if event.Actor == "" || event.Action == "" || event.ResourceType == "" || event.ResourceID == "" {
+		return InvalidEvent
+	}
+	if event.RequestID == "" {
+		for _, field := range simplelog.Fields(ctx) {
+			if field.Key == RequestIDKey {
+				event.RequestID = field.String
+			}
+		}
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.broken != nil {
+		return l.broken
+	}
+	if event.Time.IsZero() {
+		event.Time = l.now().UTC()
+	}
+	entry, err := json.Marshal(Entry{Sequence: l.sequence + 1, PrevHash: l.lastHash, Event: event})
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %w", err)
+	}
+	hash := chainHash(l.key, l.lastHash, entry)
+	line, err := json.Marshal(Record{Hash: hash, Entry: entry})
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	line = append(line, '\n')
+	n, err := l.writer.Write(line)
+	if err == nil && n < len(line) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		if n > 0 {
+			l.discardPartial()
+		}
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	l.offset += int64(n)
+	l.sequence++
+	l.lastHash = hash
+	return nil
+}
+
+// discardPartial cuts the partial record a failed write left, which would break
+// the chain of every following entry. When the sink can not be truncated the
+// logger refuses further entries.
+func (l *Logger) discardPartial() {
+	if l.truncate == nil {
+		l.broken = errors.New("audit sink holds a partial record and can not be truncated")
+		return
+	}
+	if err := l.truncate(l.offset); err != nil {
+		l.broken = fmt.Errorf("failed to truncate the partial audit record: %w", err)
+	}
+}
+
+// Sync flushes the sink to stable storage when it supports it.
+func (l *Logger) Sync() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if syncer, ok := l.writer.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+func (l *Logger) Close() error {
+	if err := l.Sync(); err != nil {
+		return err
+	}
+	if l.closer != nil {
+		return l.closer.Close()
+	}
+	return nil
+}
+
+func chainHash(key []byte, prevHash string, entry []byte) string {
+	sum := sha256.New()
+	if key != nil {
+		sum = hmac.New(sha256.New, key)
+	}
+	sum.Write([]byte(prevHash))
+	sum.Write(entry)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+type verifiedEntry struct {
+	Entry
+	hash string
+}
+
+// TamperedError tells where the chain breaks.
+type TamperedError struct {
+	Line   int
+	Reason string
+}
+
+func (e *TamperedError) Error() string {
+	return fmt.Sprintf("audit chain broken at line %d: %s", e.Line, e.Reason)
+}
+
+// PartialRecordError tells the chain ends with a record missing its newline,
+// the trace of a write interrupted by a crash rather than of tampering.
+type PartialRecordError struct {
+	Line int
+	// Offset is the size of the complete records before it.
+	Offset int64
+}
+
+func (e *PartialRecordError) Error() string {
+	return fmt.Sprintf("audit chain ends with a partial record at line %d", e.Line)
+}
+
+// Verify reads the whole chain and returns a *TamperedError at the first broken
+// link, or a *PartialRecordError when only the last record is incomplete.
+func Verify(r io.Reader, opts ...Option) error {
+	_, err := verify(r, newOptions(opts).key)
+	return err
+}
+
+// verify returns the last entry of a valid chain, nil when r is empty.
+func verify(r io.Reader, key []byte) (*verifiedEntry, error) {
+	var last *verifiedEntry
+	err := scan(r, func(line int, record Record, entry Entry) error {
+		prevHash, sequence := "", uint64(1)
+		if last != nil {
+			prevHash, sequence = last.hash, last.Sequence+1
+		}
+		switch {
+		case entry.PrevHash != prevHash:
+			return &TamperedError{Line: line, Reason: "previous hash does not match"}
+		case entry.Sequence != sequence:
+			return &TamperedError{Line: line, Reason: fmt.Sprintf("expected sequence %d, got %d", sequence, entry.Sequence)}
+		case chainHash(key, prevHash, record.Entry) != record.Hash:
+			return &TamperedError{Line: line, Reason: "hash does not match the entry"}
+		}
+		last = &verifiedEntry{Entry: entry, hash: record.Hash}
+		return nil
+	})
+	return last, err
+}
+
+// scan calls fn for every record and returns a *PartialRecordError for a last
+// line without a newline.
+func scan(r io.Reader, fn func(line int, record Record, entry Entry) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	scanner.Split(scanRecords)
+	line := 0
+	var offset int64
+	for scanner.Scan() {
+		line++
+		data, complete := bytes.CutSuffix(scanner.Bytes(), []byte("\n"))
+		if !complete {
+			return &PartialRecordError{Line: line, Offset: offset}
+		}
+		offset += int64(len(scanner.Bytes()))
+		if len(data) == 0 {
+			continue
+		}
+		var record Record
+		var entry Entry
+		if err := json.Unmarshal(data, &record); err != nil {
+			return &TamperedError{Line: line, Reason: err.Error()}
+		}
+		if err := json.Unmarshal(record.Entry, &entry); err != nil {
+			return &TamperedError{Line: line, Reason: err.Error()}
+		}
+		if err := fn(line, record, entry); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// scanRecords splits lines like bufio.ScanLines but keeps the newline, so scan
+// tells a complete last record from a partial one.
+func scanRecords(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
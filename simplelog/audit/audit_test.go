@@ -0,0 +1,246 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phuthien0308/ordering-base/simplelog"
+	"github.com/phuthien0308/ordering-base/simplelog/tags"
+)
+
+type product struct {
+	SKU      string            `json:"sku"`
+	Price    float64           `json:"price"`
+	Secret   string            `json:"secret" redact:"mask"`
+	Attrs    map[string]string `json:"attributes"`
+	internal int
+}
+
+func TestDiff(t *testing.T) {
+	before := &product{SKU: "A-1", Price: 10, Secret: "a", Attrs: map[string]string{"color": "red"}, internal: 1}
+	after := &product{SKU: "A-1", Price: 12, Secret: "b", Attrs: map[string]string{"color": "blue"}, internal: 2}
+
+	changes := Diff(before, after)
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %+v", changes)
+	}
+	if changes[0] != (Change{Field: "price", Before: 10.0, After: 12.0}) {
+		t.Errorf("unexpected price change %+v", changes[0])
+	}
+	if changes[1] != (Change{Field: "secret", Before: redactedValue, After: redactedValue}) {
+		t.Errorf("secret should be redacted, got %+v", changes[1])
+	}
+	if changes[2].Field != "attributes" {
+		t.Errorf("unexpected attributes change %+v", changes[2])
+	}
+
+	if created := Diff(nil, after); len(created) != 4 || created[0].Before != nil || created[0].After != "A-1" {
+		t.Errorf("unexpected creation diff %+v", created)
+	}
+	maps := Diff(map[string]any{"a": 1, "b": 2}, map[string]any{"b": 3, "c": 4})
+	if len(maps) != 3 || maps[0].Field != "a" || maps[0].After != nil || maps[2].Before != nil {
+		t.Errorf("unexpected map diff %+v", maps)
+	}
+}
+
+func TestLoggerChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := simplelog.With(context.Background(), tags.String(RequestIDKey, "r-1"))
+	for _, event := range []Event{
+		{Actor: "alice", Action: "create", ResourceType: "product", ResourceID: "A-1"},
+		{Actor: "bob", Action: "update", ResourceType: "product", ResourceID: "B-1"},
+	} {
+		if err := logger.Log(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := logger.Log(ctx, Event{Actor: "alice"}); !errors.Is(err, InvalidEvent) {
+		t.Errorf("expected InvalidEvent, got %v", err)
+	}
+	logger.Close()
+
+	// reopening continues the chain.
+	logger, err = OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = logger.Log(ctx, Event{Actor: "bob", Action: "delete", ResourceType: "product", ResourceID: "A-1",
+		Changes: Diff(&product{SKU: "A-1"}, nil), RequestID: "r-2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Close()
+
+	entries, err := ReadFile(path, Query{ResourceType: "product", ResourceID: "A-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Action != "create" || entries[0].RequestID != "r-1" ||
+		entries[1].Sequence != 3 || entries[1].RequestID != "r-2" || len(entries[1].Changes) != 4 {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+	if since, _ := ReadFile(path, Query{Actor: "bob", Since: time.Now().Add(-time.Minute)}); len(since) != 2 {
+		t.Errorf("expected 2 entries by bob, got %+v", since)
+	}
+
+	content, _ := os.ReadFile(path)
+	if err := Verify(bytes.NewReader(content)); err != nil {
+		t.Fatalf("expected a valid chain: %v", err)
+	}
+	tampered := strings.Replace(string(content), `"actor":"bob"`, `"actor":"eve"`, 1)
+	var tamperedErr *TamperedError
+	if err := Verify(strings.NewReader(tampered)); !errors.As(err, &tamperedErr) || tamperedErr.Line != 2 {
+		t.Errorf("expected the tampering to be detected on line 2, got %v", err)
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if err := Verify(strings.NewReader(lines[0] + lines[2])); !errors.As(err, &tamperedErr) {
+		t.Errorf("expected a removed line to be detected, got %v", err)
+	}
+	if err := os.WriteFile(path, []byte(tampered), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFile(path); !errors.As(err, &tamperedErr) {
+		t.Errorf("a tampered file must not be extended, got %v", err)
+	}
+}
+
+// shortWriter writes half of the record it fails on.
+type shortWriter struct {
+	bytes.Buffer
+	fail bool
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if w.fail {
+		w.fail = false
+		n, _ := w.Buffer.Write(p[:len(p)/2])
+		return n, errors.New("disk full")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestLoggerPartialWrite(t *testing.T) {
+	event := Event{Actor: "alice", Action: "create", ResourceType: "product", ResourceID: "A-1"}
+	sink := &shortWriter{}
+	logger := &Logger{writer: sink, now: time.Now,
+		truncate: func(size int64) error { sink.Truncate(int(size)); return nil }}
+	for _, fail := range []bool{false, true, false} {
+		sink.fail = fail
+		if err := logger.Log(context.Background(), event); (err != nil) != fail {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	if err := Verify(bytes.NewReader(sink.Bytes())); err != nil {
+		t.Errorf("expected the partial record to be truncated: %v", err)
+	}
+	if lines := strings.Count(sink.String(), "\n"); lines != 2 {
+		t.Errorf("expected 2 records, got %d", lines)
+	}
+
+	// a sink that can not be truncated is never extended after a partial record.
+	logger = NewLogger(&shortWriter{fail: true})
+	if err := logger.Log(context.Background(), event); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	if err := logger.Log(context.Background(), event); err == nil {
+		t.Error("expected the logger to refuse entries after a partial record")
+	}
+}
+
+func TestOpenFileCutsPartialRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	event := Event{Actor: "alice", Action: "create", ResourceType: "product", ResourceID: "A-1"}
+	logger, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(context.Background(), event)
+	logger.Close()
+	complete, _ := os.ReadFile(path)
+	// a crash in the middle of the second record.
+	if err := os.WriteFile(path, append(complete, complete[:len(complete)/2]...), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(path)
+	var partial *PartialRecordError
+	if err := Verify(bytes.NewReader(content)); !errors.As(err, &partial) || partial.Line != 2 {
+		t.Errorf("expected a partial record on line 2, got %v", err)
+	}
+	if entries, err := ReadFile(path, Query{}); err != nil || len(entries) != 1 {
+		t.Errorf("expected the complete entry only, got %+v, %v", entries, err)
+	}
+	logger, err = OpenFile(path)
+	if err != nil {
+		t.Fatalf("expected the partial record to be cut: %v", err)
+	}
+	if err := logger.Log(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	logger.Close()
+	content, _ = os.ReadFile(path)
+	if err := Verify(bytes.NewReader(content)); err != nil || strings.Count(string(content), "\n") != 2 {
+		t.Errorf("expected 2 valid records, got %v in %q", err, content)
+	}
+}
+
+func TestKeyedChain(t *testing.T) {
+	var sink bytes.Buffer
+	logger := NewLogger(&sink, WithKey([]byte("secret")))
+	for _, actor := range []string{"alice", "bob"} {
+		event := Event{Actor: actor, Action: "update", ResourceType: "product", ResourceID: "A-1"}
+		if err := logger.Log(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Verify(bytes.NewReader(sink.Bytes()), WithKey([]byte("secret"))); err != nil {
+		t.Fatalf("expected a valid chain: %v", err)
+	}
+
+	// a chain recomputed without the key does not verify.
+	forged := &bytes.Buffer{}
+	forger := NewLogger(forged)
+	for _, actor := range []string{"alice", "eve"} {
+		forger.Log(context.Background(), Event{Actor: actor, Action: "update", ResourceType: "product", ResourceID: "A-1"})
+	}
+	var tamperedErr *TamperedError
+	if err := Verify(bytes.NewReader(forged.Bytes()), WithKey([]byte("secret"))); !errors.As(err, &tamperedErr) || tamperedErr.Line != 1 {
+		t.Errorf("expected the forged chain to be rejected, got %v", err)
+	}
+	if err := Verify(bytes.NewReader(sink.Bytes())); !errors.As(err, &tamperedErr) {
+		t.Errorf("expected a keyed chain to need its key, got %v", err)
+	}
+}
+
+func TestDiffRedactsByKey(t *testing.T) {
+	type account struct {
+		Username string `json:"username"`
+		APIToken string `json:"apiToken"`
+		Contact  string `json:"contact"`
+	}
+	changes := Diff(&account{Username: "john", APIToken: "a", Contact: "old"},
+		&account{Username: "john", APIToken: "b", Contact: "john@example.com"})
+	if len(changes) != 2 || changes[0] != (Change{Field: "apiToken", Before: redactedValue, After: redactedValue}) ||
+		changes[1] != (Change{Field: "contact", Before: "old", After: redactedValue}) {
+		t.Errorf("unexpected changes %+v", changes)
+	}
+
+	changes = Diff(map[string]any{"password": "old", "profile": map[string]string{"secret": "x"}},
+		map[string]any{"password": "new", "profile": map[string]string{"secret": "y"}})
+	if len(changes) != 2 || changes[0].Field != "password" || changes[0].After != redactedValue {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+	if profile := changes[1].After.(map[string]any); profile["secret"] != redactedValue {
+		t.Errorf("expected the nested secret to be masked, got %+v", profile)
+	}
+}
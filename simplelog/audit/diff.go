@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/phuthien0308/ordering-base/simplelog"
+)
+
+const redactedValue = "[REDACTED]"
+
+// redactor holds the simplelog default rules, the audit file is append-only so
+// a sensitive value written to it can never be cleaned.
+var redactor = simplelog.NewRedactor()
+
+// Diff lists the exported fields that differ between before and after, which
+// are structs of the same type (or pointers to them) or maps with string keys.
+// A nil before or after describes a creation or a deletion. Struct fields
+// carrying the simplelog redact tag, and fields or map keys matching the
+// simplelog key rules, are replaced by a placeholder; the other values go
+// through the simplelog value rules.
+func Diff(before, after any) []Change {
+	b, a := indirect(reflect.ValueOf(before)), indirect(reflect.ValueOf(after))
+	var changes []Change
+	switch {
+	case isKind(b, reflect.Struct) || isKind(a, reflect.Struct):
+		if b.IsValid() && a.IsValid() && b.Type() != a.Type() {
+			return nil
+		}
+		sample := b
+		if !sample.IsValid() {
+			sample = a
+		}
+		structType := sample.Type()
+		for i := 0; i < structType.NumField(); i++ {
+			field := structType.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := fieldName(field)
+			if name == "" {
+				continue
+			}
+			changes = appendChange(changes, name, fieldValue(b, i), fieldValue(a, i), field.Tag.Get(simplelog.RedactTag) != "")
+		}
+	case isKind(b, reflect.Map) || isKind(a, reflect.Map):
+		keys := make(map[string]bool)
+		for _, m := range []reflect.Value{b, a} {
+			if isKind(m, reflect.Map) && m.Type().Key().Kind() == reflect.String {
+				for _, key := range m.MapKeys() {
+					keys[key.String()] = true
+				}
+			}
+		}
+		names := make([]string, 0, len(keys))
+		for key := range keys {
+			names = append(names, key)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			changes = appendChange(changes, name, mapValue(b, name), mapValue(a, name), false)
+		}
+	}
+	return changes
+}
+
+func appendChange(changes []Change, name string, before, after reflect.Value, redact bool) []Change {
+	if before.IsValid() && after.IsValid() && reflect.DeepEqual(before.Interface(), after.Interface()) {
+		return changes
+	}
+	if !before.IsValid() && !after.IsValid() {
+		return changes
+	}
+	change := Change{Field: name, Before: valueOf(before), After: valueOf(after)}
+	if redact || redactor.KeyAction(name) != 0 {
+		change.Before, change.After = redactedValue, redactedValue
+		return append(changes, change)
+	}
+	change.Before, change.After = redactValue(change.Before), redactValue(change.After)
+	return append(changes, change)
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func isKind(v reflect.Value, kind reflect.Kind) bool {
+	return v.IsValid() && v.Kind() == kind
+}
+
+func fieldName(field reflect.StructField) string {
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		return field.Name
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func fieldValue(v reflect.Value, i int) reflect.Value {
+	if !v.IsValid() {
+		return reflect.Value{}
+	}
+	return v.Field(i)
+}
+
+func mapValue(v reflect.Value, key string) reflect.Value {
+	if !isKind(v, reflect.Map) {
+		return reflect.Value{}
+	}
+	return v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+}
+
+// redactValue keeps the nil of a creation or deletion, a value dropped by a rule is masked.
+func redactValue(value any) any {
+	if value == nil {
+		return nil
+	}
+	redacted, ok := redactor.Value(value)
+	if !ok {
+		return redactedValue
+	}
+	return redacted
+}
+
+func valueOf(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Query selects entries, empty fields match everything.
+type Query struct {
+	ResourceType string
+	ResourceID   string
+	Actor        string
+	Since        time.Time
+	Until        time.Time
+}
+
+func (q Query) match(entry Entry) bool {
+	return (q.ResourceType == "" || q.ResourceType == entry.ResourceType) &&
+		(q.ResourceID == "" || q.ResourceID == entry.ResourceID) &&
+		(q.Actor == "" || q.Actor == entry.Actor) &&
+		(q.Since.IsZero() || !entry.Time.Before(q.Since)) &&
+		(q.Until.IsZero() || entry.Time.Before(q.Until))
+}
+
+// Read returns the entries matching q in chain order. It does not verify the
+// chain, use Verify for that, and skips a partial last record.
+func Read(r io.Reader, q Query) ([]Entry, error) {
+	var entries []Entry
+	err := scan(r, func(_ int, _ Record, entry Entry) error {
+		if q.match(entry) {
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	var partial *PartialRecordError
+	if errors.As(err, &partial) {
+		err = nil
+	}
+	return entries, err
+}
+
+// ReadFile returns the entries of the audit file at path matching q, e.g. the
+// history of one product:
+//
+//	audit.ReadFile(path, audit.Query{ResourceType: "product", ResourceID: sku})
+func ReadFile(path string, q Query) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	defer file.Close()
+	return Read(file, q)
+}
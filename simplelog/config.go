@@ -0,0 +1,295 @@
+package simplelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type SamplingConfig struct {
+	// Initial entries with the same level and message logged every second, then every Thereafter-th.
+	Initial    int `json:"initial"`
+	Thereafter int `json:"thereafter"`
+}
+
+// Config describes how to build a SimpleLogger.
+type Config struct {
+	Level            string            `json:"level"`
+	Encoding         string            `json:"encoding"`
+	OutputPaths      []string          `json:"outputPaths"`
+	ErrorOutputPaths []string          `json:"errorOutputPaths"`
+	Sampling         *SamplingConfig   `json:"sampling"`
+	Caller           bool              `json:"caller"`
+	StacktraceLevel  string            `json:"stacktraceLevel"`
+	Service          string            `json:"service"`
+	Environment      string            `json:"environment"`
+	Fields           map[string]string `json:"fields"`
+	// Redact applies NewRedactor to every field, on by default in production.
+	Redact bool `json:"redact"`
+	// File also writes the entries to a rotated file through a FileSink.
+	File *FileSinkConfig `json:"file"`
+}
+
+func NewProductionConfig() Config {
+	return Config{
+		Level:            "info",
+		Encoding:         "json",
+		OutputPaths:      []string{"stderr"},
+		ErrorOutputPaths: []string{"stderr"},
+		Sampling:         &SamplingConfig{Initial: 100, Thereafter: 100},
+		Caller:           true,
+		StacktraceLevel:  "error",
+		Redact:           true,
+	}
+}
+
+func NewDevelopmentConfig() Config {
+	return Config{
+		Level:            "debug",
+		Encoding:         "console",
+		OutputPaths:      []string{"stderr"},
+		ErrorOutputPaths: []string{"stderr"},
+		Caller:           true,
+		StacktraceLevel:  "warn",
+	}
+}
+
+// LoadConfig reads a JSON config file on top of the production defaults.
+func LoadConfig(path string) (Config, error) {
+	cfg := NewProductionConfig()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read log config: %w", err)
+	}
+	if err := json.Unmarshal(content, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse log config: %w", err)
+	}
+	return cfg, nil
+}
+
+// ConfigFromEnv overrides the production defaults with the SIMPLELOG_* environment variables.
+// Lists are comma separated, SIMPLELOG_FIELDS holds key=value pairs. SIMPLELOG_FILE
+// adds a file sink with the default settings, use LoadConfig to tune its rotation.
+func ConfigFromEnv() (Config, error) {
+	cfg := NewProductionConfig()
+	if v, ok := os.LookupEnv("SIMPLELOG_LEVEL"); ok {
+		cfg.Level = v
+	}
+	if v, ok := os.LookupEnv("SIMPLELOG_ENCODING"); ok {
+		cfg.Encoding = v
+	}
+	if v, ok := os.LookupEnv("SIMPLELOG_OUTPUT_PATHS"); ok {
+		cfg.OutputPaths = splitList(v)
+	}
+	if v, ok := os.LookupEnv("SIMPLELOG_ERROR_OUTPUT_PATHS"); ok {
+		cfg.ErrorOutputPaths = splitList(v)
+	}
+	if v, ok := os.LookupEnv("SIMPLELOG_SAMPLING"); ok {
+		if v == "" || v == "off" {
+			cfg.Sampling = nil
+		} else {
+			initial, thereafter, found := strings.Cut(v, ",")
+			first, err1 := strconv.Atoi(strings.TrimSpace(initial))
+			then, err2 := strconv.Atoi(strings.TrimSpace(thereafter))
+			if !found || err1 != nil || err2 != nil {
+				return cfg, fmt.Errorf("invalid SIMPLELOG_SAMPLING %q, expected initial,thereafter", v)
+			}
+			cfg.Sampling = &SamplingConfig{Initial: first, Thereafter: then}
+		}
+	}
+	if v, ok := os.LookupEnv("SIMPLELOG_CALLER"); ok {
+		caller, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid SIMPLELOG_CALLER %q: %w", v, err)
+		}
+		cfg.Caller = caller
+	}
+	if v, ok := os.LookupEnv("SIMPLELOG_REDACT"); ok {
+		redact, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid SIMPLELOG_REDACT %q: %w", v, err)
+		}
+		cfg.Redact = redact
+	}
+	if v, ok := os.LookupEnv("SIMPLELOG_STACKTRACE_LEVEL"); ok {
+		cfg.StacktraceLevel = v
+	}
+	if v, ok := os.LookupEnv("SIMPLELOG_SERVICE"); ok {
+		cfg.Service = v
+	}
+	if v, ok := os.LookupEnv("SIMPLELOG_ENVIRONMENT"); ok {
+		cfg.Environment = v
+	}
+	if v, ok := os.LookupEnv("SIMPLELOG_FILE"); ok && v != "" {
+		cfg.File = &FileSinkConfig{Filename: v}
+	}
+	if v, ok := os.LookupEnv("SIMPLELOG_FIELDS"); ok {
+		cfg.Fields = make(map[string]string)
+		for _, pair := range splitList(v) {
+			key, value, found := strings.Cut(pair, "=")
+			if !found {
+				return cfg, fmt.Errorf("invalid SIMPLELOG_FIELDS entry %q, expected key=value", pair)
+			}
+			cfg.Fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return cfg, nil
+}
+
+func splitList(v string) []string {
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func (c Config) Validate() error {
+	if _, err := zapcore.ParseLevel(c.Level); err != nil {
+		return fmt.Errorf("invalid level: %w", err)
+	}
+	if c.StacktraceLevel != "" {
+		if _, err := zapcore.ParseLevel(c.StacktraceLevel); err != nil {
+			return fmt.Errorf("invalid stacktrace level: %w", err)
+		}
+	}
+	if c.Encoding != "json" && c.Encoding != "console" {
+		return fmt.Errorf("invalid encoding %q, expected json or console", c.Encoding)
+	}
+	if len(c.OutputPaths) == 0 && c.File == nil {
+		return fmt.Errorf("at least one output path or a file is required")
+	}
+	if c.File != nil {
+		if err := c.File.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Sampling != nil && (c.Sampling.Initial < 0 || c.Sampling.Thereafter <= 0) {
+		return fmt.Errorf("sampling initial must not be negative and thereafter must be positive, disable sampling with a nil Sampling")
+	}
+	return nil
+}
+
+// zapConfig enables every level in the core, the configured level is applied
+// by the root component so named components can go below it.
+func (c Config) zapConfig() zap.Config {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	if c.Encoding == "console" {
+		encoderConfig = zap.NewDevelopmentEncoderConfig()
+	}
+	zapConfig := zap.Config{
+		Level:             zap.NewAtomicLevelAt(zapcore.DebugLevel),
+		Encoding:          c.Encoding,
+		EncoderConfig:     encoderConfig,
+		OutputPaths:       c.OutputPaths,
+		ErrorOutputPaths:  c.ErrorOutputPaths,
+		DisableCaller:     !c.Caller,
+		DisableStacktrace: true,
+		InitialFields:     make(map[string]interface{}),
+	}
+	if c.Sampling != nil {
+		zapConfig.Sampling = &zap.SamplingConfig{Initial: c.Sampling.Initial, Thereafter: c.Sampling.Thereafter}
+	}
+	for key, value := range c.Fields {
+		zapConfig.InitialFields[key] = value
+	}
+	if c.Service != "" {
+		zapConfig.InitialFields["service"] = c.Service
+	}
+	if c.Environment != "" {
+		zapConfig.InitialFields["environment"] = c.Environment
+	}
+	return zapConfig
+}
+
+// newFileCore mirrors the core zap builds from zapConfig: its initial fields and
+// sampling are applied before the options, so they do not reach a teed core.
+func newFileCore(zapConfig zap.Config, sink zapcore.WriteSyncer) zapcore.Core {
+	encoder := zapcore.NewJSONEncoder(zapConfig.EncoderConfig)
+	if zapConfig.Encoding == "console" {
+		encoder = zapcore.NewConsoleEncoder(zapConfig.EncoderConfig)
+	}
+	keys := make([]string, 0, len(zapConfig.InitialFields))
+	for key := range zapConfig.InitialFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fields := make([]zapcore.Field, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, zap.Any(key, zapConfig.InitialFields[key]))
+	}
+	core := zapcore.NewCore(encoder, sink, zapConfig.Level).With(fields)
+	if zapConfig.Sampling != nil {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, zapConfig.Sampling.Initial, zapConfig.Sampling.Thereafter)
+	}
+	return core
+}
+
+// New validates cfg and builds a SimpleLogger from it.
+func New(cfg Config, opts ...Option) (*SimpleLogger, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	zapOptions := []zap.Option{zap.AddCallerSkip(1)}
+	if cfg.StacktraceLevel != "" {
+		stacktraceLevel, _ := zapcore.ParseLevel(cfg.StacktraceLevel)
+		zapOptions = append(zapOptions, zap.AddStacktrace(stacktraceLevel))
+	}
+	zapConfig := cfg.zapConfig()
+	var sink *FileSink
+	if cfg.File != nil {
+		var err error
+		sink, err = NewFileSink(*cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		fileCore := newFileCore(zapConfig, sink)
+		zapOptions = append(zapOptions, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, fileCore)
+		}))
+	}
+	zapLogger, err := zapConfig.Build(zapOptions...)
+	if err != nil {
+		if sink != nil {
+			sink.Close()
+		}
+		return nil, fmt.Errorf("failed to build logger: %w", err)
+	}
+	if cfg.Redact {
+		opts = append([]Option{WithRedactor(NewRedactor())}, opts...)
+	}
+	level, _ := zapcore.ParseLevel(cfg.Level)
+	logger := newSimpleLogger(zapLogger, level, opts)
+	logger.sink = sink
+	return logger, nil
+}
+
+// Close flushes and closes the file sink of the config the logger was built
+// from, if any. Call it once the logger and the loggers derived from it are no
+// longer used.
+func (logger *SimpleLogger) Close() error {
+	if logger.sink == nil {
+		return nil
+	}
+	return logger.sink.Close()
+}
+
+// Init builds a logger from cfg and installs it as the global logger.
+// The global logger is left untouched when cfg is invalid.
+func Init(cfg Config, opts ...Option) (*SimpleLogger, error) {
+	logger, err := New(cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ReplaceGlobals(logger)
+	return logger, nil
+}
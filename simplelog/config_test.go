@@ -0,0 +1,96 @@
+package simplelog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("SIMPLELOG_LEVEL", "debug")
+	t.Setenv("SIMPLELOG_ENCODING", "console")
+	t.Setenv("SIMPLELOG_SAMPLING", "off")
+	t.Setenv("SIMPLELOG_CALLER", "false")
+	t.Setenv("SIMPLELOG_SERVICE", "productservice")
+	t.Setenv("SIMPLELOG_FIELDS", "region=ap-southeast-1, team=catalog")
+
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "debug" || cfg.Encoding != "console" || cfg.Sampling != nil || cfg.Caller ||
+		cfg.Service != "productservice" || cfg.Fields["team"] != "catalog" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+
+	t.Setenv("SIMPLELOG_SAMPLING", "10")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("expected an error for a malformed sampling value")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.json")
+	content := `{"level": "warn", "outputPaths": ["stdout"], "environment": "staging"}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "warn" || cfg.OutputPaths[0] != "stdout" || cfg.Environment != "staging" || cfg.Encoding != "json" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tcs := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "level", modify: func(c *Config) { c.Level = "verbose" }},
+		{name: "encoding", modify: func(c *Config) { c.Encoding = "xml" }},
+		{name: "outputs", modify: func(c *Config) { c.OutputPaths = nil }},
+		{name: "stacktrace", modify: func(c *Config) { c.StacktraceLevel = "loud" }},
+		{name: "sampling", modify: func(c *Config) { c.Sampling = &SamplingConfig{Initial: -1} }},
+		{name: "sampling drops everything", modify: func(c *Config) { c.Sampling = &SamplingConfig{Initial: 0, Thereafter: 0} }},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewProductionConfig()
+			tc.modify(&cfg)
+			if err := cfg.Validate(); err == nil {
+				t.Error("expected a validation error")
+			}
+		})
+	}
+}
+
+func TestInitReplacesGlobal(t *testing.T) {
+	original := L()
+	cfg := NewDevelopmentConfig()
+	cfg.OutputPaths = []string{filepath.Join(t.TempDir(), "app.log")}
+	cfg.Service = "productservice"
+
+	logger, err := Init(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ReplaceGlobals(original)
+	if L() != logger {
+		t.Fatal("global logger was not replaced")
+	}
+	L().Info(context.Background(), "hello")
+	_ = L().Sync()
+	content, _ := os.ReadFile(cfg.OutputPaths[0])
+	if len(content) == 0 {
+		t.Error("expected the entry in the configured output")
+	}
+
+	cfg.Level = "nope"
+	if _, err := Init(cfg); err == nil || L() != logger {
+		t.Error("an invalid config must not replace the global logger")
+	}
+}
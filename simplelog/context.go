@@ -0,0 +1,38 @@
+package simplelog
+
+import (
+	"context"
+
+	"github.com/phuthien0308/ordering-base/simplelog/tags"
+)
+
+// With returns a copy of ctx carrying the fields already in ctx plus the given ones.
+// A field whose key already exists replaces the previous value in place.
+func With(ctx context.Context, fields ...tags.T) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, SimpleLogKeyCtx, merge(Fields(ctx), fields))
+}
+
+// Fields returns the log fields attached to ctx.
+func Fields(ctx context.Context) []tags.T {
+	fields, _ := ctx.Value(SimpleLogKeyCtx).([]tags.T)
+	return fields
+}
+
+func merge(existing []tags.T, fields []tags.T) []tags.T {
+	merged := make([]tags.T, 0, len(existing)+len(fields))
+	index := make(map[string]int, len(existing)+len(fields))
+	for _, list := range [][]tags.T{existing, fields} {
+		for _, field := range list {
+			if i, ok := index[field.Key]; ok {
+				merged[i] = field
+				continue
+			}
+			index[field.Key] = len(merged)
+			merged = append(merged, field)
+		}
+	}
+	return merged
+}
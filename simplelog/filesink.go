@@ -0,0 +1,448 @@
+package simplelog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var SinkClosed = errors.New("file sink closed")
+
+// OverflowPolicy decides what Write does when the queue of a FileSink is full.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock makes the caller wait for room in the queue.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDrop discards the entry and counts it in FileSink.Dropped.
+	OverflowDrop OverflowPolicy = "drop"
+)
+
+const (
+	defaultQueueSize = 1024
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+)
+
+// FileSinkConfig describes a rotated log file. Durations are Go durations such as "24h".
+type FileSinkConfig struct {
+	Filename string `json:"filename"`
+	// MaxSize in bytes rotates the file before a write would grow it past the limit, 0 disables it.
+	MaxSize int64 `json:"maxSize"`
+	// RotateEvery rotates the file once it has been open that long, empty disables it.
+	RotateEvery string `json:"rotateEvery"`
+	// MaxBackups rotated files are kept, 0 keeps all of them.
+	MaxBackups int `json:"maxBackups"`
+	// MaxAge removes rotated files older than it, empty keeps them.
+	MaxAge   string `json:"maxAge"`
+	Compress bool   `json:"compress"`
+	// QueueSize entries are buffered before the overflow policy applies, defaults to 1024.
+	QueueSize int            `json:"queueSize"`
+	Overflow  OverflowPolicy `json:"overflow"`
+}
+
+func (c FileSinkConfig) Validate() error {
+	if c.Filename == "" {
+		return fmt.Errorf("file sink filename is required")
+	}
+	if c.MaxSize < 0 || c.MaxBackups < 0 || c.QueueSize < 0 {
+		return fmt.Errorf("file sink sizes must not be negative")
+	}
+	if _, err := parseOptionalDuration(c.RotateEvery); err != nil {
+		return fmt.Errorf("invalid file sink rotateEvery: %w", err)
+	}
+	if _, err := parseOptionalDuration(c.MaxAge); err != nil {
+		return fmt.Errorf("invalid file sink maxAge: %w", err)
+	}
+	if c.Overflow != "" && c.Overflow != OverflowBlock && c.Overflow != OverflowDrop {
+		return fmt.Errorf("invalid file sink overflow %q, expected block or drop", c.Overflow)
+	}
+	return nil
+}
+
+func parseOptionalDuration(v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err == nil && d < 0 {
+		err = fmt.Errorf("negative duration %q", v)
+	}
+	return d, err
+}
+
+// FileSink is a zapcore.WriteSyncer writing to a rotated file from a background
+// goroutine. Rotated files are renamed to name-<timestamp>.ext and optionally
+// gzipped. Sync returns once every entry queued before it is written and synced.
+type FileSink struct {
+	cfg         FileSinkConfig
+	rotateEvery time.Duration
+	maxAge      time.Duration
+	now         func() time.Time
+
+	// mutex guards closed against the queue being closed during a Write.
+	mutex   sync.RWMutex
+	closed  bool
+	queue   chan sinkRequest
+	done    chan struct{}
+	dropped atomic.Uint64
+
+	// owned by the writer goroutine.
+	file     *os.File
+	buffer   *bufio.Writer
+	size     int64
+	openedAt time.Time
+	err      error
+
+	// compressions receives the rotated files to gzip, and the Sync markers, when
+	// Compress is set, so a large file never blocks the writer goroutine.
+	compressions    chan compressRequest
+	compressionDone chan struct{}
+	// compressionErr holds the errors left when the compression goroutine stops.
+	compressionErr error
+}
+
+type sinkRequest struct {
+	entry []byte
+	// synced receives the result of a Sync.
+	synced chan error
+}
+
+type compressRequest struct {
+	backup string
+	// synced receives err joined with the compression errors since the previous Sync.
+	synced chan error
+	err    error
+}
+
+// NewFileSink opens (or creates) the file and starts the writer goroutine.
+func NewFileSink(cfg FileSinkConfig) (*FileSink, error) {
+	return newFileSink(cfg, time.Now)
+}
+
+func newFileSink(cfg FileSinkConfig, now func() time.Time) (*FileSink, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.QueueSize == 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	if cfg.Overflow == "" {
+		cfg.Overflow = OverflowBlock
+	}
+	sink := &FileSink{
+		cfg:   cfg,
+		now:   now,
+		queue: make(chan sinkRequest, cfg.QueueSize),
+		done:  make(chan struct{}),
+	}
+	sink.rotateEvery, _ = parseOptionalDuration(cfg.RotateEvery)
+	sink.maxAge, _ = parseOptionalDuration(cfg.MaxAge)
+	if err := os.MkdirAll(filepath.Dir(cfg.Filename), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+	if cfg.Compress {
+		sink.compressions = make(chan compressRequest, 16)
+		sink.compressionDone = make(chan struct{})
+		go sink.compress()
+	}
+	go sink.run()
+	return sink, nil
+}
+
+// Write queues a copy of p, zap reuses its buffers once Write returns.
+func (s *FileSink) Write(p []byte) (int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closed {
+		return 0, SinkClosed
+	}
+	request := sinkRequest{entry: append([]byte(nil), p...)}
+	if s.cfg.Overflow == OverflowDrop {
+		select {
+		case s.queue <- request:
+		default:
+			s.dropped.Add(1)
+		}
+		return len(p), nil
+	}
+	s.queue <- request
+	return len(p), nil
+}
+
+// Dropped returns how many entries the drop policy discarded.
+func (s *FileSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Sync waits for the queued entries to be written and synced to disk, it
+// returns the first write error met since the previous Sync.
+func (s *FileSink) Sync() error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closed {
+		return SinkClosed
+	}
+	synced := make(chan error, 1)
+	s.queue <- sinkRequest{synced: synced}
+	return <-synced
+}
+
+// Close flushes the queue, waits for the pending compressions and closes the
+// file. It returns the errors not reported by a Sync yet, later writes fail with
+// SinkClosed.
+func (s *FileSink) Close() error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return SinkClosed
+	}
+	s.closed = true
+	close(s.queue)
+	s.mutex.Unlock()
+	<-s.done
+	if s.compressions != nil {
+		close(s.compressions)
+		<-s.compressionDone
+	}
+	return errors.Join(s.err, s.compressionErr, s.flush(), s.file.Close())
+}
+
+func (s *FileSink) run() {
+	defer close(s.done)
+	for request := range s.queue {
+		if request.synced != nil {
+			err := s.flush()
+			if err == nil {
+				err = s.err
+			}
+			s.err = nil
+			if s.compressions != nil {
+				s.compressions <- compressRequest{synced: request.synced, err: err}
+				continue
+			}
+			request.synced <- err
+			continue
+		}
+		if err := s.write(request.entry); err != nil && s.err == nil {
+			s.err = err
+		}
+		// the buffer only batches entries already waiting in the queue.
+		if len(s.queue) == 0 {
+			if err := s.buffer.Flush(); err != nil && s.err == nil {
+				s.err = err
+			}
+		}
+	}
+}
+
+func (s *FileSink) write(entry []byte) error {
+	tooBig := s.cfg.MaxSize > 0 && s.size > 0 && s.size+int64(len(entry)) > s.cfg.MaxSize
+	tooOld := s.rotateEvery > 0 && s.size > 0 && s.now().Sub(s.openedAt) >= s.rotateEvery
+	var rotateErr error
+	if tooBig || tooOld {
+		rotateErr = s.rotate()
+	}
+	n, err := s.buffer.Write(entry)
+	s.size += int64(n)
+	return errors.Join(rotateErr, err)
+}
+
+func (s *FileSink) flush() error {
+	if err := s.buffer.Flush(); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.cfg.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	s.file, s.size, s.openedAt = file, info.Size(), s.now()
+	if s.buffer == nil {
+		s.buffer = bufio.NewWriterSize(file, 64*1024)
+	} else {
+		s.buffer.Reset(file)
+	}
+	return nil
+}
+
+// rotate renames the current file, reopens a fresh one, then prunes the backups
+// or hands the new one to the compression goroutine. Failures are reported, the
+// entry is still written.
+func (s *FileSink) rotate() error {
+	if err := s.buffer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush log file: %w", err)
+	}
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	backup := s.backupName(s.now())
+	renameErr := os.Rename(s.cfg.Filename, backup)
+	// keep logging to the same file when it could not be renamed.
+	if err := s.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return fmt.Errorf("failed to rotate log file: %w", renameErr)
+	}
+	if s.compressions != nil {
+		select {
+		case s.compressions <- compressRequest{backup: backup}:
+			return nil
+		default:
+			// the writer never waits for the compressions, the next one prunes.
+			return fmt.Errorf("compression queue full, %s is left uncompressed", backup)
+		}
+	}
+	return s.prune()
+}
+
+// compress gzips the rotated files in the background and prunes the backups
+// once no compression is queued, so a queued file is not removed under it. Its
+// errors are returned by the next Sync or by Close.
+func (s *FileSink) compress() {
+	defer close(s.compressionDone)
+	var errs []error
+	prune := false
+	for request := range s.compressions {
+		if request.backup != "" {
+			errs = append(errs, compressFile(request.backup))
+			prune = true
+		}
+		if prune && (request.synced != nil || len(s.compressions) == 0) {
+			errs = append(errs, s.prune())
+			prune = false
+		}
+		if request.synced != nil {
+			request.synced <- errors.Join(append([]error{request.err}, errs...)...)
+			errs = nil
+		}
+	}
+	s.compressionErr = errors.Join(errs...)
+}
+
+func (s *FileSink) backupName(t time.Time) string {
+	dir, prefix, ext := s.nameParts()
+	name := filepath.Join(dir, prefix+t.UTC().Format(backupTimeFormat)+ext)
+	// two rotations within a millisecond must not overwrite each other.
+	for i := 1; fileExists(name) || fileExists(name+".gz"); i++ {
+		name = filepath.Join(dir, fmt.Sprintf("%s%s.%d%s", prefix, t.UTC().Format(backupTimeFormat), i, ext))
+	}
+	return name
+}
+
+func (s *FileSink) nameParts() (dir string, prefix string, ext string) {
+	base := filepath.Base(s.cfg.Filename)
+	ext = filepath.Ext(base)
+	return filepath.Dir(s.cfg.Filename), strings.TrimSuffix(base, ext) + "-", ext
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+func compressFile(name string) error {
+	source, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		// pruned while it waited.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to compress log file: %w", err)
+	}
+	defer source.Close()
+	target, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to compress log file: %w", err)
+	}
+	writer := gzip.NewWriter(target)
+	_, err = io.Copy(writer, source)
+	err = errors.Join(err, writer.Close(), target.Close())
+	if err != nil {
+		os.Remove(name + ".gz")
+		return fmt.Errorf("failed to compress log file: %w", err)
+	}
+	return os.Remove(name)
+}
+
+type backupFile struct {
+	path string
+	time time.Time
+}
+
+// backups lists the rotated files, newest first.
+func (s *FileSink) backups() ([]backupFile, error) {
+	dir, prefix, ext := s.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log backups: %w", err)
+	}
+	var backups []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ext)
+		stamp = strings.TrimPrefix(stamp, prefix)
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		t, err := time.Parse(backupTimeFormat, stamp[:len(backupTimeFormat)])
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, name), time: t})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].time.Equal(backups[j].time) {
+			return backups[i].time.After(backups[j].time)
+		}
+		// same millisecond, the higher counter suffix is the newer one.
+		if len(backups[i].path) != len(backups[j].path) {
+			return len(backups[i].path) > len(backups[j].path)
+		}
+		return backups[i].path > backups[j].path
+	})
+	return backups, nil
+}
+
+func (s *FileSink) prune() error {
+	if s.cfg.MaxBackups == 0 && s.maxAge == 0 {
+		return nil
+	}
+	backups, err := s.backups()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i, backup := range backups {
+		expired := s.maxAge > 0 && s.now().Sub(backup.time) > s.maxAge
+		if (s.cfg.MaxBackups > 0 && i >= s.cfg.MaxBackups) || expired {
+			if err := os.Remove(backup.path); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove log backup: %w", err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
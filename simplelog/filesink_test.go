@@ -0,0 +1,255 @@
+package simplelog
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(time.Millisecond)
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestFileSinkSizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+	sink, err := newFileSink(FileSinkConfig{
+		Filename:   filepath.Join(dir, "app.log"),
+		MaxSize:    30,
+		MaxBackups: 2,
+		Compress:   true,
+	}, clock.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	for _, line := range []string{"first entry\n", "second entry\n", "third entry\n", "fourth entry\n", "fifth entry\n", "sixth entry\n"} {
+		if _, err := sink.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	names := listDir(t, dir)
+	if len(names) != 3 {
+		t.Fatalf("expected the log file and 2 backups, got %v", names)
+	}
+	current, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+	if string(current) != "fifth entry\nsixth entry\n" {
+		t.Errorf("unexpected current file %q", current)
+	}
+	// the newest backup holds the entries written just before the last rotation.
+	newest := filepath.Join(dir, names[1])
+	if !strings.HasPrefix(names[1], "app-2024-05-01T10-00-00.") || !strings.HasSuffix(newest, ".log.gz") {
+		t.Fatalf("unexpected backup name %s", names[1])
+	}
+	file, err := os.Open(newest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(reader)
+	if string(content) != "third entry\nfourth entry\n" {
+		t.Errorf("unexpected backup content %q", content)
+	}
+}
+
+func TestFileSinkTimeRotationAndMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+	expired := filepath.Join(dir, "app-2024-04-01T10-00-00.000.log")
+	if err := os.WriteFile(expired, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sink, err := newFileSink(FileSinkConfig{
+		Filename:    filepath.Join(dir, "app.log"),
+		RotateEvery: "1h",
+		MaxAge:      "168h",
+	}, clock.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	sink.Write([]byte("before\n"))
+	sink.Write([]byte("still the same hour\n"))
+	sink.Sync()
+	clock.Add(time.Hour)
+	sink.Write([]byte("after\n"))
+	if err := sink.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	names := listDir(t, dir)
+	if len(names) != 2 || !strings.HasPrefix(names[0], "app-2024-05-01T11-00-00.") {
+		t.Fatalf("expected one fresh backup and the expired one removed, got %v", names)
+	}
+	backup, _ := os.ReadFile(filepath.Join(dir, names[0]))
+	if string(backup) != "before\nstill the same hour\n" {
+		t.Errorf("unexpected backup content %q", backup)
+	}
+}
+
+func TestFileSinkOverflowDrop(t *testing.T) {
+	dir := t.TempDir()
+	var stalled atomic.Bool
+	release := make(chan struct{})
+	now := func() time.Time {
+		// the writer goroutine asks for the time before each entry.
+		if stalled.Load() {
+			<-release
+		}
+		return time.Now()
+	}
+	sink, err := newFileSink(FileSinkConfig{
+		Filename:    filepath.Join(dir, "app.log"),
+		RotateEvery: "24h",
+		QueueSize:   1,
+		Overflow:    OverflowDrop,
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.Write([]byte("written\n"))
+	sink.Sync()
+	stalled.Store(true)
+
+	sink.Write([]byte("picked by the writer\n"))
+	for len(sink.queue) > 0 {
+		runtime.Gosched()
+	}
+	for sink.Dropped() == 0 {
+		sink.Write([]byte("queued or dropped\n"))
+	}
+	stalled.Store(false)
+	close(release)
+
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sink.Write([]byte("late\n")); !errors.Is(err, SinkClosed) {
+		t.Errorf("expected SinkClosed, got %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+	if lines := strings.Count(string(content), "\n"); lines != 3 {
+		t.Errorf("expected the dropped entries to be missing, got %q", content)
+	}
+}
+
+func TestNewWithFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	cfg := NewProductionConfig()
+	cfg.OutputPaths = nil
+	cfg.Service = "productservice"
+	cfg.File = &FileSinkConfig{Filename: path, MaxSize: 1 << 20}
+
+	logger, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info(t.Context(), "written to the file")
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"msg":"written to the file"`) ||
+		!strings.Contains(string(content), `"service":"productservice"`) {
+		t.Errorf("unexpected file content %q", content)
+	}
+
+	cfg.File = &FileSinkConfig{Filename: path, Overflow: "spill"}
+	if _, err := New(cfg); err == nil {
+		t.Error("expected an invalid overflow policy to be rejected")
+	}
+
+	goroutines := runtime.NumGoroutine()
+	cfg.OutputPaths = []string{filepath.Join(t.TempDir(), "missing", "stdout.log")}
+	cfg.File = &FileSinkConfig{Filename: path, MaxSize: 1 << 20, Compress: true}
+	if _, err := New(cfg); err == nil {
+		t.Fatal("expected an unreachable output to fail the build")
+	}
+	if n := runtime.NumGoroutine(); n != goroutines {
+		t.Errorf("expected the file sink to be closed, %d goroutines left of %d", n, goroutines)
+	}
+}
+
+func TestCloseReleasesFileSink(t *testing.T) {
+	dir := t.TempDir()
+	cfg := NewProductionConfig()
+	cfg.OutputPaths = nil
+	cfg.File = &FileSinkConfig{Filename: filepath.Join(dir, "app.log"), MaxSize: 100, Compress: true}
+
+	goroutines := runtime.NumGoroutine()
+	logger, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 4 {
+		logger.Info(t.Context(), "rotated into a compressed backup")
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := runtime.NumGoroutine(); n != goroutines {
+		t.Errorf("expected Close to stop the sink, %d goroutines left of %d", n, goroutines)
+	}
+	var compressed int
+	for _, name := range listDir(t, dir) {
+		if strings.HasSuffix(name, ".gz") {
+			compressed++
+		}
+	}
+	if compressed == 0 {
+		t.Errorf("expected the queued backups to be compressed before Close returns, got %v", listDir(t, dir))
+	}
+	if err := NewSimpleLogger(zap.NewNop()).Close(); err != nil {
+		t.Errorf("expected Close without a file sink to do nothing, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package simplelog
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RootComponent is the name of the logger the components are derived from.
+const RootComponent = "root"
+
+var ComponentNotExists = errors.New("Component not existed")
+
+// ComponentLevel describes the current level of a component.
+type ComponentLevel struct {
+	Component string    `json:"component"`
+	Level     string    `json:"level"`
+	ResetAt   time.Time `json:"resetAt,omitzero"`
+}
+
+// LevelRegistry holds the atomic levels of a logger and of its named components.
+// Component levels can go below the root level only when the underlying core
+// accepts them, which is the case for loggers built by New.
+type LevelRegistry struct {
+	mutex      sync.Mutex
+	base       zapcore.Core
+	components map[string]*component
+}
+
+type component struct {
+	logger   *SimpleLogger
+	level    zap.AtomicLevel
+	previous zapcore.Level
+	reset    *time.Timer
+	resetAt  time.Time
+}
+
+// levelCore filters a shared core with its own atomic level.
+type levelCore struct {
+	zapcore.Core
+	level zap.AtomicLevel
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level) && c.Core.Enabled(level)
+}
+
+func (c *levelCore) Level() zapcore.Level {
+	return c.level.Level()
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// withLevels puts logger under a new registry as its root component.
+func withLevels(logger *SimpleLogger, level zapcore.Level) *SimpleLogger {
+	registry := &LevelRegistry{base: logger.Core(), components: make(map[string]*component)}
+	root := &component{level: zap.NewAtomicLevelAt(level)}
+	logger.Logger = logger.WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return &levelCore{Core: registry.base, level: root.level}
+	}))
+	logger.levels = registry
+	root.logger = logger
+	registry.components[RootComponent] = root
+	return logger
+}
+
+// Levels returns the level registry of the logger.
+func (logger *SimpleLogger) Levels() *LevelRegistry {
+	return logger.levels
+}
+
+// Named returns the child logger of the component, creating it with the current
+// root level the first time. Its level is then controlled independently.
+func (logger *SimpleLogger) Named(name string) *SimpleLogger {
+	registry := logger.levels
+	if registry == nil {
+		child := *logger
+		child.Logger = logger.Logger.Named(name)
+		return &child
+	}
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if existing, ok := registry.components[name]; ok {
+		return existing.logger
+	}
+	root := registry.components[RootComponent]
+	created := &component{level: zap.NewAtomicLevelAt(root.level.Level())}
+	child := *root.logger
+	child.Logger = root.logger.WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return &levelCore{Core: registry.base, level: created.level}
+	})).Named(name)
+	created.logger = &child
+	registry.components[name] = created
+	return created.logger
+}
+
+// Named returns the named child of the global logger.
+func Named(name string) *SimpleLogger {
+	return L().Named(name)
+}
+
+// Components lists every component sorted by name.
+func (r *LevelRegistry) Components() []ComponentLevel {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	levels := make([]ComponentLevel, 0, len(r.components))
+	for name, c := range r.components {
+		levels = append(levels, ComponentLevel{Component: name, Level: c.level.String(), ResetAt: c.resetAt})
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Component < levels[j].Component })
+	return levels
+}
+
+func (r *LevelRegistry) Level(name string) (ComponentLevel, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	c, ok := r.components[name]
+	if !ok {
+		return ComponentLevel{}, ComponentNotExists
+	}
+	return ComponentLevel{Component: name, Level: c.level.String(), ResetAt: c.resetAt}, nil
+}
+
+// SetLevel changes the level of a component. With a positive ttl the level
+// goes back to its value before the change once the ttl expires.
+func (r *LevelRegistry) SetLevel(name string, level zapcore.Level, ttl time.Duration) (ComponentLevel, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	c, ok := r.components[name]
+	if !ok {
+		return ComponentLevel{}, ComponentNotExists
+	}
+	if c.reset != nil {
+		// keep the level from before the first temporary change.
+		c.reset.Stop()
+	} else {
+		c.previous = c.level.Level()
+	}
+	c.reset, c.resetAt = nil, time.Time{}
+	c.level.SetLevel(level)
+	if ttl > 0 {
+		c.resetAt = time.Now().Add(ttl)
+		var timer *time.Timer
+		timer = time.AfterFunc(ttl, func() {
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+			if c.reset != timer {
+				return
+			}
+			c.level.SetLevel(c.previous)
+			c.reset, c.resetAt = nil, time.Time{}
+		})
+		c.reset = timer
+	}
+	return ComponentLevel{Component: name, Level: c.level.String(), ResetAt: c.resetAt}, nil
+}
@@ -0,0 +1,84 @@
+package simplelog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelRequest changes the level of a component, TTL is a Go duration such as "10m".
+type LevelRequest struct {
+	Component string `json:"component"`
+	Level     string `json:"level"`
+	TTL       string `json:"ttl,omitempty"`
+}
+
+// Apply validates the request and changes the level in the registry.
+func (r *LevelRegistry) Apply(request LevelRequest) (ComponentLevel, error) {
+	level, err := zapcore.ParseLevel(request.Level)
+	if err != nil {
+		return ComponentLevel{}, fmt.Errorf("invalid level: %w", err)
+	}
+	var ttl time.Duration
+	if request.TTL != "" {
+		if ttl, err = time.ParseDuration(request.TTL); err != nil {
+			return ComponentLevel{}, fmt.Errorf("invalid ttl: %w", err)
+		}
+	}
+	return r.SetLevel(request.Component, level, ttl)
+}
+
+// LevelHandler serves the component levels of the registry:
+// GET lists every component (or the one given by ?component=),
+// PUT and POST take a LevelRequest body.
+func LevelHandler(registry *LevelRegistry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			name := r.URL.Query().Get("component")
+			if name == "" {
+				writeJSON(w, http.StatusOK, registry.Components())
+				return
+			}
+			level, err := registry.Level(name)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, level)
+		case http.MethodPut, http.MethodPost:
+			var request LevelRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			level, err := registry.Apply(request)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, level)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		}
+	})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusBadRequest
+	if errors.Is(err, ComponentNotExists) {
+		code = http.StatusNotFound
+	}
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
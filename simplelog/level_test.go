@@ -0,0 +1,95 @@
+package simplelog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNamedComponentLevel(t *testing.T) {
+	zapCore, observerLogs := observer.New(zap.DebugLevel)
+	root := newSimpleLogger(zap.New(zapCore), zap.InfoLevel, nil)
+	lb := root.Named("simplelb")
+	if root.Named("simplelb") != lb {
+		t.Fatal("expected the same logger for the same component")
+	}
+
+	lb.Debug(context.Background(), "hidden")
+	if _, err := root.Levels().SetLevel("simplelb", zap.DebugLevel, 0); err != nil {
+		t.Fatal(err)
+	}
+	lb.Debug(context.Background(), "pulled addresses")
+	root.Debug(context.Background(), "still hidden")
+
+	entries := observerLogs.All()
+	if len(entries) != 1 || entries[0].Message != "pulled addresses" || entries[0].LoggerName != "simplelb" {
+		t.Errorf("expected only the simplelb debug entry, got %v", entries)
+	}
+}
+
+func TestSetLevelWithTTL(t *testing.T) {
+	root := newSimpleLogger(zap.NewNop(), zap.InfoLevel, nil)
+	root.Named("simplelb")
+	level, err := root.Levels().SetLevel("simplelb", zap.DebugLevel, 10*time.Millisecond)
+	if err != nil || level.Level != "debug" || level.ResetAt.IsZero() {
+		t.Fatalf("unexpected level %+v err %v", level, err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if level, _ := root.Levels().Level("simplelb"); level.Level == "info" && level.ResetAt.IsZero() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("level was not reset after the ttl")
+}
+
+func TestLevelHandler(t *testing.T) {
+	root := newSimpleLogger(zap.NewNop(), zap.InfoLevel, nil)
+	root.Named("simplelb")
+	handler := LevelHandler(root.Levels())
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/log/levels", nil))
+	var levels []ComponentLevel
+	if err := json.NewDecoder(recorder.Body).Decode(&levels); err != nil {
+		t.Fatal(err)
+	}
+	if len(levels) != 2 || levels[0].Component != RootComponent || levels[1].Component != "simplelb" {
+		t.Errorf("unexpected components %+v", levels)
+	}
+
+	recorder = httptest.NewRecorder()
+	body := `{"component": "simplelb", "level": "debug", "ttl": "1m"}`
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/log/levels", strings.NewReader(body)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status %v: %v", recorder.Code, recorder.Body)
+	}
+	if level, _ := root.Levels().Level("simplelb"); level.Level != zapcore.DebugLevel.String() {
+		t.Errorf("level was not changed, got %+v", level)
+	}
+
+	tcs := []struct {
+		body string
+		code int
+	}{
+		{body: `{"component": "unknown", "level": "debug"}`, code: http.StatusNotFound},
+		{body: `{"component": "simplelb", "level": "verbose"}`, code: http.StatusBadRequest},
+		{body: `{"component": "simplelb", "level": "debug", "ttl": "soon"}`, code: http.StatusBadRequest},
+	}
+	for _, tc := range tcs {
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/log/levels", strings.NewReader(tc.body)))
+		if recorder.Code != tc.code {
+			t.Errorf("%s: expected %v, got %v", tc.body, tc.code, recorder.Code)
+		}
+	}
+}
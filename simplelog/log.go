@@ -2,9 +2,14 @@ package simplelog
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync/atomic"
 
 	"github.com/phuthien0308/ordering-base/simplelog/tags"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 type SimpleLogKey struct{}
@@ -13,48 +18,154 @@ var SimpleLogKeyCtx = SimpleLogKey{}
 
 type SimpleLogger struct {
 	*zap.Logger
+	// spanEvents copies Warn and Error entries to the active span.
+	spanEvents bool
+	// baggageKeys are the baggage members logged as fields.
+	baggageKeys []string
+	levels      *LevelRegistry
+	sampler     *siteSampler
+	// sink is the file sink built by New, closed by Close.
+	sink *FileSink
 }
 
-var zapLogger, _ = zap.NewProduction(zap.AddCaller(),
-	zap.AddStacktrace(zap.ErrorLevel))
+type Option func(*SimpleLogger)
 
-var Logger = NewSimpleLogger(zapLogger)
+// WithSpanEvents records Warn and Error entries as events on the span found in the
+// context, Error entries also mark the span status as error.
+func WithSpanEvents() Option {
+	return func(logger *SimpleLogger) {
+		logger.spanEvents = true
+	}
+}
 
-func NewSimpleLogger(logger *zap.Logger) *SimpleLogger {
-	return &SimpleLogger{logger}
+// WithBaggageFields logs the members of the OpenTelemetry baggage found in the
+// context under keys, e.g. tenant_id, as string fields. Fields attached with
+// With win over baggage members of the same key.
+func WithBaggageFields(keys ...string) Option {
+	return func(logger *SimpleLogger) {
+		logger.baggageKeys = keys
+	}
 }
 
-func (logger *SimpleLogger) withContext(ctx context.Context) *SimpleLogger {
-	if fields, ok := ctx.Value(SimpleLogKeyCtx).([]zap.Field); ok {
-		return &SimpleLogger{logger.With(fields...)}
+// Logger is the default logger built from NewProductionConfig, installed as the
+// global logger at startup. ReplaceGlobals does not update it.
+//
+// Deprecated: use L, which returns the current global logger and is safe to call
+// while the global logger is replaced.
+var Logger = newDefaultLogger()
+
+var global atomic.Pointer[SimpleLogger]
+
+func init() {
+	global.Store(Logger)
+}
+
+func newDefaultLogger() *SimpleLogger {
+	logger, err := New(NewProductionConfig())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "simplelog: can not build the default logger, logging is disabled: %v\n", err)
+		return NewSimpleLogger(zap.NewNop())
 	}
 	return logger
 }
 
+// L returns the global logger.
+func L() *SimpleLogger {
+	return global.Load()
+}
+
+// ReplaceGlobals installs logger as the global logger and returns a function restoring the previous one.
+func ReplaceGlobals(logger *SimpleLogger) func() {
+	previous := global.Swap(logger)
+	return func() {
+		ReplaceGlobals(previous)
+	}
+}
+
+func NewSimpleLogger(logger *zap.Logger, opts ...Option) *SimpleLogger {
+	return newSimpleLogger(logger, zapcore.LevelOf(logger.Core()), opts)
+}
+
+func newSimpleLogger(logger *zap.Logger, level zapcore.Level, opts []Option) *SimpleLogger {
+	simpleLogger := &SimpleLogger{Logger: logger}
+	for _, opt := range opts {
+		opt(simpleLogger)
+	}
+	return withLevels(simpleLogger, level)
+}
+
+// withContext returns the fields of ctx followed by fields. They are added to the
+// entry once it passed the level check, rather than to a clone of the logger.
+func (logger *SimpleLogger) withContext(ctx context.Context, fields []tags.T) []tags.T {
+	contextFields := Fields(ctx)
+	if members := logger.baggageFields(ctx); len(members) > 0 {
+		contextFields = merge(members, contextFields)
+	}
+	if correlation := traceFields(ctx); len(correlation) > 0 {
+		contextFields = merge(contextFields, correlation)
+	}
+	if len(contextFields) == 0 {
+		return fields
+	}
+	return slices.Concat(contextFields, fields)
+}
+
 func (logger *SimpleLogger) Debug(ctx context.Context, msg string, fields ...tags.T) {
-	logger.withContext(ctx).Logger.Debug(msg, fields...)
+	fields, ok := logger.sample(zapcore.DebugLevel, fields)
+	if !ok {
+		return
+	}
+	if checked := logger.Check(zapcore.DebugLevel, msg); checked != nil {
+		checked.Write(logger.withContext(ctx, fields)...)
+	}
 }
 
 func (logger *SimpleLogger) Info(ctx context.Context, msg string, fields ...tags.T) {
-	logger.withContext(ctx).Logger.Info(msg, fields...)
+	fields, ok := logger.sample(zapcore.InfoLevel, fields)
+	if !ok {
+		return
+	}
+	if checked := logger.Check(zapcore.InfoLevel, msg); checked != nil {
+		checked.Write(logger.withContext(ctx, fields)...)
+	}
 }
 
 func (logger *SimpleLogger) Warn(ctx context.Context, msg string, fields ...tags.T) {
-	logger.withContext(ctx).Logger.Warn(msg, fields...)
+	fields, ok := logger.sample(zapcore.WarnLevel, fields)
+	if !ok {
+		return
+	}
+	if checked := logger.Check(zapcore.WarnLevel, msg); checked != nil {
+		checked.Write(logger.withContext(ctx, fields)...)
+	}
+	logger.recordSpanEvent(ctx, zapcore.WarnLevel, msg, fields)
 }
 
 func (logger *SimpleLogger) Error(ctx context.Context, msg string, fields ...tags.T) {
-	logger.withContext(ctx).Logger.Error(msg, fields...)
+	fields, ok := logger.sample(zapcore.ErrorLevel, fields)
+	if !ok {
+		return
+	}
+	if checked := logger.Check(zapcore.ErrorLevel, msg); checked != nil {
+		checked.Write(logger.withContext(ctx, fields)...)
+	}
+	logger.recordSpanEvent(ctx, zapcore.ErrorLevel, msg, fields)
 }
 
 func (logger *SimpleLogger) DPanic(ctx context.Context, msg string, fields ...tags.T) {
-	logger.withContext(ctx).Logger.DPanic(msg, fields...)
+	if checked := logger.Check(zapcore.DPanicLevel, msg); checked != nil {
+		checked.Write(logger.withContext(ctx, fields)...)
+	}
 }
 
 func (logger *SimpleLogger) Panic(ctx context.Context, msg string, fields ...tags.T) {
-	logger.withContext(ctx).Logger.Panic(msg, fields...)
+	if checked := logger.Check(zapcore.PanicLevel, msg); checked != nil {
+		checked.Write(logger.withContext(ctx, fields)...)
+	}
 }
 
 func (logger *SimpleLogger) Fatal(ctx context.Context, msg string, fields ...tags.T) {
-	logger.withContext(ctx).Logger.Fatal(msg, fields...)
+	if checked := logger.Check(zapcore.FatalLevel, msg); checked != nil {
+		checked.Write(logger.withContext(ctx, fields)...)
+	}
 }
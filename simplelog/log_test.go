@@ -2,6 +2,8 @@ package simplelog
 
 import (
 	"context"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/phuthien0308/ordering-base/simplelog/tags"
@@ -26,3 +28,65 @@ func TestLogger(t *testing.T) {
 	}
 
 }
+
+func TestWithMergesFields(t *testing.T) {
+	zapCore, observerLogs := observer.New(zap.InfoLevel)
+	logger := NewSimpleLogger(zap.New(zapCore))
+
+	ctx := With(context.TODO(), tags.String("request-id", "1"), tags.String("service", "product"))
+	ctx = With(ctx, tags.String("sku", "A-1"), tags.String("request-id", "2"))
+	logger.Info(ctx, "hello world")
+
+	fields := Fields(ctx)
+	if len(fields) != 3 || fields[0].Key != "request-id" || fields[0].String != "2" {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+	expected := map[string]interface{}{"request-id": "2", "service": "product", "sku": "A-1"}
+	for key, value := range expected {
+		if got := observerLogs.All()[0].ContextMap()[key]; got != value {
+			t.Errorf("expected %v=%v, got %v", key, value, got)
+		}
+	}
+	if Fields(context.TODO()) != nil {
+		t.Error("expected no fields on an empty context")
+	}
+}
+
+func TestDisabledLevelSkipsContextFields(t *testing.T) {
+	zapCore, observerLogs := observer.New(zap.InfoLevel)
+	logger := NewSimpleLogger(zap.New(zapCore, zap.AddCaller(), zap.AddCallerSkip(1)))
+	ctx := With(context.Background(), tags.String("request-id", "1"))
+
+	if allocs := testing.AllocsPerRun(100, func() { logger.Debug(ctx, "disabled") }); allocs != 0 {
+		t.Errorf("expected a disabled entry not to allocate, got %v allocations", allocs)
+	}
+	logger.Info(ctx, "enabled")
+	entry := observerLogs.All()[0]
+	if entry.ContextMap()["request-id"] != "1" || !strings.HasSuffix(entry.Caller.File, "log_test.go") {
+		t.Errorf("unexpected fields %v or caller %s", entry.ContextMap(), entry.Caller)
+	}
+}
+
+func TestReplaceGlobalsConcurrently(t *testing.T) {
+	original, before := L(), Logger
+	defer ReplaceGlobals(original)
+	replacement := NewSimpleLogger(zap.NewNop())
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			restore := ReplaceGlobals(replacement)
+			restore()
+		}()
+		go func() {
+			defer wg.Done()
+			L().Debug(context.Background(), "while replacing")
+		}()
+	}
+	wg.Wait()
+	if Logger != before {
+		t.Error("ReplaceGlobals must not write the deprecated Logger variable")
+	}
+}
@@ -0,0 +1,455 @@
+package simplelog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RedactAction tells what happens to a sensitive value.
+type RedactAction int
+
+const (
+	// Mask replaces the value with a fixed placeholder.
+	Mask RedactAction = iota + 1
+	// Hash replaces the value with a short sha256 so equal values can still be correlated.
+	Hash
+	// Drop removes the field (or the struct field) entirely.
+	Drop
+)
+
+const redactedValue = "[REDACTED]"
+
+// RedactTag is the struct tag read on values logged with tags.Any, e.g. `redact:"hash"`.
+const RedactTag = "redact"
+
+var (
+	EmailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	CardNumberPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+)
+
+var defaultRedactedKeys = []string{"password", "passwd", "secret", "token", "authorization", "apikey", "cookie"}
+
+type patternRule struct {
+	pattern  *regexp.Regexp
+	action   RedactAction
+	validate func(string) bool
+}
+
+// Redactor rewrites log fields before they reach the encoder. Rules are
+// matched on whole segments of field keys, split on '_', '-', '.' and case
+// changes and compared case-insensitively, on string values through patterns
+// and on struct fields through RedactTag.
+type Redactor struct {
+	keys map[string]RedactAction
+	// order lists the keys longest first so the most specific rule wins, e.g.
+	// accesstoken before token.
+	order    []string
+	patterns []patternRule
+}
+
+// NewRedactor masks credentials by key and emails by value. Card numbers are
+// opt-in, a Luhn check alone also matches one in ten long numeric ids:
+//
+//	redactor := simplelog.NewRedactor().RedactPattern(simplelog.CardNumberPattern, simplelog.Mask, simplelog.Luhn)
+func NewRedactor() *Redactor {
+	r := &Redactor{keys: make(map[string]RedactAction)}
+	for _, key := range defaultRedactedKeys {
+		r.RedactKey(key, Mask)
+	}
+	r.RedactPattern(EmailPattern, Mask, nil)
+	return r
+}
+
+// RedactKey applies action to the fields whose key contains the segments of
+// key, e.g. token matches access_token and accessToken but not tokenizer. When
+// several keys match, the longest one decides.
+func (r *Redactor) RedactKey(key string, action RedactAction) *Redactor {
+	normalized := normalizeKey(key)
+	if _, ok := r.keys[normalized]; !ok {
+		r.order = append(r.order, normalized)
+		slices.SortFunc(r.order, func(a, b string) int {
+			if len(a) != len(b) {
+				return len(b) - len(a)
+			}
+			return strings.Compare(a, b)
+		})
+	}
+	r.keys[normalized] = action
+	return r
+}
+
+// RedactPattern applies action to string values matching pattern; validate, if set,
+// filters out false positives. Mask and Hash rewrite the match only, Drop removes the field.
+func (r *Redactor) RedactPattern(pattern *regexp.Regexp, action RedactAction, validate func(string) bool) *Redactor {
+	r.patterns = append(r.patterns, patternRule{pattern: pattern, action: action, validate: validate})
+	return r
+}
+
+// Luhn reports whether the digits in s form a valid card number.
+func Luhn(s string) bool {
+	sum, count := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if count%2 == 1 {
+			if digit *= 2; digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		count++
+	}
+	return count >= 13 && sum%10 == 0
+}
+
+func normalizeKey(key string) string {
+	return strings.Join(keySegments(key), "")
+}
+
+// keySegments splits key on separators and case changes, e.g. X-APIKey and
+// x_api_key both give x, api, key.
+func keySegments(key string) []string {
+	var segments []string
+	start := -1
+	for i, c := range key {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			if start >= 0 {
+				segments = append(segments, strings.ToLower(key[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(c) {
+			previous, _ := utf8.DecodeLastRuneInString(key[:i])
+			next, _ := utf8.DecodeRuneInString(key[i+utf8.RuneLen(c):])
+			if !unicode.IsUpper(previous) || unicode.IsLower(next) {
+				segments = append(segments, strings.ToLower(key[start:i]))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		segments = append(segments, strings.ToLower(key[start:]))
+	}
+	return segments
+}
+
+// KeyAction returns the action of the longest key rule matching key, zero when none does.
+func (r *Redactor) KeyAction(key string) RedactAction {
+	segments := keySegments(key)
+	// the runs of consecutive segments, so access_token matches accessToken.
+	runs := make(map[string]bool, len(segments)*(len(segments)+1)/2)
+	for i := range segments {
+		for j := i + 1; j <= len(segments); j++ {
+			runs[strings.Join(segments[i:j], "")] = true
+		}
+	}
+	for _, sensitive := range r.order {
+		if runs[sensitive] {
+			return r.keys[sensitive]
+		}
+	}
+	return 0
+}
+
+func apply(action RedactAction, value string) string {
+	if action == Hash {
+		sum := sha256.Sum256([]byte(value))
+		return "sha256:" + hex.EncodeToString(sum[:6])
+	}
+	return redactedValue
+}
+
+// redactString returns the rewritten string, or false when the value must be dropped.
+func (r *Redactor) redactString(value string) (string, bool) {
+	for _, rule := range r.patterns {
+		dropped := false
+		value = rule.pattern.ReplaceAllStringFunc(value, func(match string) string {
+			if rule.validate != nil && !rule.validate(match) {
+				return match
+			}
+			if rule.action == Drop {
+				dropped = true
+			}
+			return apply(rule.action, match)
+		})
+		if dropped {
+			return "", false
+		}
+	}
+	return value, true
+}
+
+// Fields returns the redacted copy of fields.
+func (r *Redactor) Fields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, 0, len(fields))
+	for _, field := range fields {
+		if field, ok := r.field(field); ok {
+			redacted = append(redacted, field)
+		}
+	}
+	return redacted
+}
+
+func (r *Redactor) field(field zapcore.Field) (zapcore.Field, bool) {
+	if action := r.KeyAction(field.Key); action != 0 {
+		if action == Drop {
+			return field, false
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+		return zap.String(field.Key, apply(action, fmt.Sprint(enc.Fields[field.Key]))), true
+	}
+	switch field.Type {
+	case zapcore.StringType:
+		value, ok := r.redactString(field.String)
+		return zap.String(field.Key, value), ok
+	case zapcore.ReflectType:
+		if field.Interface == nil {
+			return field, true
+		}
+		value, ok := r.value(reflect.ValueOf(field.Interface), 0)
+		return zap.Any(field.Key, value), ok
+	case zapcore.StringerType:
+		stringer, ok := field.Interface.(fmt.Stringer)
+		if !ok {
+			return field, true
+		}
+		value, ok := r.redactString(stringer.String())
+		return zap.String(field.Key, value), ok
+	case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType:
+		// objects such as tags.Proto and tags.Err are encoded to a map first so their
+		// keys and values go through the same rules, a failing one is left to the encoder.
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+		encoded, ok := enc.Fields[field.Key]
+		if !ok {
+			return field, true
+		}
+		value, ok := r.Value(encoded)
+		return zap.Any(field.Key, value), ok
+	}
+	return field, true
+}
+
+// Value returns the redacted copy of v, walked like tags.Any values, and false
+// when a rule drops it.
+func (r *Redactor) Value(v any) (any, bool) {
+	if v == nil {
+		return nil, true
+	}
+	return r.value(reflect.ValueOf(v), 0)
+}
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	marshalerType = reflect.TypeFor[json.Marshaler]()
+)
+
+const maxRedactDepth = 10
+
+// value walks structs, maps and slices the way encoding/json would, applying every rule.
+func (r *Redactor) value(v reflect.Value, depth int) (any, bool) {
+	if !v.IsValid() {
+		return nil, true
+	}
+	if depth > maxRedactDepth || v.Type() == timeType || v.Type().Implements(marshalerType) {
+		return v.Interface(), true
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, true
+		}
+		return r.value(v.Elem(), depth+1)
+	case reflect.String:
+		return r.redactString(v.String())
+	case reflect.Struct:
+		out := make(map[string]any, v.NumField())
+		var embedded []map[string]any
+		for i := 0; i < v.NumField(); i++ {
+			structField := v.Type().Field(i)
+			name := structField.Name
+			jsonTag, hasTag := structField.Tag.Lookup("json")
+			tagName, tagOptions, _ := strings.Cut(jsonTag, ",")
+			if jsonTag == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+			// embedded structs are flattened like encoding/json does, the
+			// fields of the outer struct win.
+			if fieldType := structField.Type; structField.Anonymous && tagName == "" {
+				if fieldType.Kind() == reflect.Pointer {
+					fieldType = fieldType.Elem()
+				}
+				if fieldType.Kind() == reflect.Struct {
+					value, _ := r.value(v.Field(i), depth+1)
+					if fields, ok := value.(map[string]any); ok {
+						embedded = append(embedded, fields)
+						continue
+					}
+					if value == nil {
+						continue
+					}
+				}
+			}
+			if !structField.IsExported() {
+				continue
+			}
+			if hasTag && strings.Contains(","+tagOptions+",", ",omitempty,") && isEmptyValue(v.Field(i)) {
+				continue
+			}
+			action := tagAction(structField.Tag.Get(RedactTag))
+			if action == 0 {
+				action = r.KeyAction(name)
+			}
+			if action == Drop {
+				continue
+			}
+			if action != 0 {
+				out[name] = apply(action, fmt.Sprint(v.Field(i).Interface()))
+				continue
+			}
+			if value, ok := r.value(v.Field(i), depth+1); ok {
+				out[name] = value
+			}
+		}
+		for _, fields := range embedded {
+			for name, value := range fields {
+				if _, ok := out[name]; !ok {
+					out[name] = value
+				}
+			}
+		}
+		return out, true
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface(), true
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if action := r.KeyAction(key); action != 0 {
+				if action != Drop {
+					out[key] = apply(action, fmt.Sprint(iter.Value().Interface()))
+				}
+				continue
+			}
+			if value, ok := r.value(iter.Value(), depth+1); ok {
+				out[key] = value
+			}
+		}
+		return out, true
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface(), true
+		}
+		out := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if value, ok := r.value(v.Index(i), depth+1); ok {
+				out = append(out, value)
+			}
+		}
+		return out, true
+	}
+	return v.Interface(), true
+}
+
+// isEmptyValue reports whether omitempty leaves v out, as in encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+func tagAction(tag string) RedactAction {
+	switch tag {
+	case "mask", "true":
+		return Mask
+	case "hash":
+		return Hash
+	case "drop", "-":
+		return Drop
+	}
+	return 0
+}
+
+// redactCore applies a Redactor to every field before delegating to the wrapped core.
+type redactCore struct {
+	zapcore.Core
+	redactor *Redactor
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redactor.Fields(fields)), redactor: c.redactor}
+}
+
+// Check delegates to the wrapped core so its own decisions, such as sampling or
+// the levels of the cores of a tee, still apply. The entry it checked is written
+// with the redacted fields.
+func (c *redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	inner := c.Core.Check(entry, nil)
+	if inner == nil {
+		return checked
+	}
+	writer := &redactWriter{Core: c.Core, redactor: c.redactor, inner: inner}
+	checked = checked.AddCore(entry, writer)
+	writer.outer = checked
+	return checked
+}
+
+func (c *redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, c.redactor.Fields(fields))
+}
+
+// redactWriter writes an entry checked by the wrapped core with the redacted fields.
+type redactWriter struct {
+	zapcore.Core
+	redactor *Redactor
+	inner    *zapcore.CheckedEntry
+	outer    *zapcore.CheckedEntry
+}
+
+func (w *redactWriter) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	// the logger adds the caller and the stack to the outer entry after Check.
+	w.inner.Entry = entry
+	w.inner.ErrorOutput = w.outer.ErrorOutput
+	w.inner.Write(w.redactor.Fields(fields)...)
+	return nil
+}
+
+// WithRedactor redacts every field logged through the logger and its components.
+func WithRedactor(redactor *Redactor) Option {
+	return func(logger *SimpleLogger) {
+		logger.Logger = logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return &redactCore{Core: core, redactor: redactor}
+		}))
+	}
+}
@@ -0,0 +1,189 @@
+package simplelog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phuthien0308/ordering-base/simplelog/tags"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type account struct {
+	Username string
+	Email    string `redact:"hash"`
+	Password string `redact:"drop"`
+	Note     string `json:"note"`
+	Internal string `json:"-"`
+}
+
+func TestRedactor(t *testing.T) {
+	zapCore, observerLogs := observer.New(zap.InfoLevel)
+	logger := NewSimpleLogger(zap.New(zapCore), WithRedactor(NewRedactor().RedactPattern(CardNumberPattern, Mask, Luhn)))
+
+	ctx := With(context.Background(), tags.String("authorization", "Bearer abc"))
+	logger.Info(ctx, "create account",
+		tags.String("access_token", "abc"),
+		tags.String("comment", "mail john@example.com, card 4111 1111 1111 1111, order 1234567890123"),
+		tags.Any("account", account{Username: "john", Email: "john@example.com", Password: "secret",
+			Note: "call 4111111111111111", Internal: "x"}),
+		tags.Any("headers", map[string]string{"Cookie": "session=1", "Accept": "json"}),
+	)
+
+	fields := observerLogs.All()[0].ContextMap()
+	if fields["authorization"] != redactedValue || fields["access_token"] != redactedValue {
+		t.Errorf("credentials were not masked: %v", fields)
+	}
+	// 1234567890123 fails the Luhn check and must be kept.
+	if expected := "mail [REDACTED], card [REDACTED], order 1234567890123"; fields["comment"] != expected {
+		t.Errorf("expected %q, got %q", expected, fields["comment"])
+	}
+
+	user := fields["account"].(map[string]any)
+	if _, ok := user["Password"]; ok {
+		t.Error("password should be dropped")
+	}
+	if user["Email"] != apply(Hash, "john@example.com") || user["Username"] != "john" {
+		t.Errorf("unexpected account %v", user)
+	}
+	if user["note"] != "call [REDACTED]" || user["Internal"] != nil {
+		t.Errorf("json tags were not honoured %v", user)
+	}
+	if headers := fields["headers"].(map[string]any); headers["Cookie"] != redactedValue || headers["Accept"] != "json" {
+		t.Errorf("unexpected headers %v", headers)
+	}
+}
+
+func TestRedactorLongestKeyWins(t *testing.T) {
+	redactor := NewRedactor().RedactKey("access_token", Hash).RedactKey("refresh-token", Drop)
+	// repeated to catch a decision depending on the map iteration order.
+	for range 20 {
+		if action := redactor.KeyAction("X-Access-Token"); action != Hash {
+			t.Fatalf("expected access_token to win over token, got %v", action)
+		}
+		if action := redactor.KeyAction("refresh_token"); action != Drop {
+			t.Fatalf("expected refresh-token to win over token, got %v", action)
+		}
+		if action := redactor.KeyAction("token"); action != Mask {
+			t.Fatalf("expected token to be masked, got %v", action)
+		}
+	}
+}
+
+func TestRedactorKeySegments(t *testing.T) {
+	redactor := NewRedactor().RedactKey("auth", Mask)
+	tests := []struct {
+		key       string
+		sensitive bool
+	}{
+		{"access_token", true},
+		{"accessToken", true},
+		{"X-API-Key", true},
+		{"APIKey", true},
+		{"user.password", true},
+		{"tokenizer_version", false},
+		{"author", false},
+		{"authMethod", true},
+		{"order_id", false},
+	}
+	for _, tt := range tests {
+		if sensitive := redactor.KeyAction(tt.key) != 0; sensitive != tt.sensitive {
+			t.Errorf("%s: expected sensitive=%v", tt.key, tt.sensitive)
+		}
+	}
+}
+
+type auditInfo struct {
+	CreatedBy string `json:"created_by"`
+	Token     string
+}
+
+type order struct {
+	auditInfo
+	*Address
+	ID      string `json:"id"`
+	Comment string `json:"comment,omitempty"`
+	Items   []int  `json:",omitempty"`
+}
+
+type Address struct {
+	City string
+}
+
+func TestRedactorStructs(t *testing.T) {
+	value, _ := NewRedactor().Value(order{auditInfo: auditInfo{CreatedBy: "john", Token: "abc"}, ID: "1720000000000000001"})
+	fields := value.(map[string]any)
+	if fields["created_by"] != "john" || fields["Token"] != redactedValue || fields["id"] != "1720000000000000001" {
+		t.Errorf("expected the embedded fields to be flattened and redacted, got %v", fields)
+	}
+	// card numbers are opt-in, a Luhn-valid id is kept by default.
+	if id, _ := NewRedactor().Value("4111111111111111"); id != "4111111111111111" {
+		t.Errorf("expected the id to be kept, got %v", id)
+	}
+	for _, key := range []string{"comment", "Items", "Address", "City", "auditInfo"} {
+		if _, ok := fields[key]; ok {
+			t.Errorf("expected %s to be left out, got %v", key, fields)
+		}
+	}
+}
+
+func TestRedactorKeepsWrappedCoreDecisions(t *testing.T) {
+	infoCore, infoLogs := observer.New(zap.InfoLevel)
+	errorCore, errorLogs := observer.New(zap.ErrorLevel)
+	logger := NewSimpleLogger(zap.New(zapcore.NewTee(infoCore, errorCore)), WithRedactor(NewRedactor()))
+
+	logger.Info(context.Background(), "login", tags.String("password", "hunter2"))
+	if infoLogs.Len() != 1 || errorLogs.Len() != 0 {
+		t.Errorf("expected only the info core to write the entry, got %d and %d", infoLogs.Len(), errorLogs.Len())
+	}
+	if password := infoLogs.All()[0].ContextMap()["password"]; password != redactedValue {
+		t.Errorf("expected the password to be redacted, got %v", password)
+	}
+}
+
+func TestLuhn(t *testing.T) {
+	for number, valid := range map[string]bool{
+		"4111 1111 1111 1111": true,
+		"5500-0000-0000-0004": true,
+		"4111 1111 1111 1112": false,
+		"0000":                false,
+	} {
+		if Luhn(number) != valid {
+			t.Errorf("Luhn(%q) should be %v", number, valid)
+		}
+	}
+}
+
+func TestProductionConfigRedacts(t *testing.T) {
+	if !NewProductionConfig().Redact || NewDevelopmentConfig().Redact {
+		t.Error("redaction should be on in production and off in development")
+	}
+
+	cfg := NewProductionConfig()
+	cfg.OutputPaths = []string{filepath.Join(t.TempDir(), "app.log")}
+	logger, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, _ := structpb.NewStruct(map[string]any{"sku": "SKU-1", "password": "hunter2", "contact": "john@example.com"})
+	logger.Error(context.Background(), "create account", tags.Proto("request", request),
+		tags.Err(fmt.Errorf("notify john@example.com: %w", errors.New("timeout"))))
+	_ = logger.Sync()
+
+	content, _ := os.ReadFile(cfg.OutputPaths[0])
+	for _, leaked := range []string{"hunter2", "john@example.com"} {
+		if strings.Contains(string(content), leaked) {
+			t.Errorf("expected %q to be redacted, got %s", leaked, content)
+		}
+	}
+	if !strings.Contains(string(content), `"sku":"SKU-1"`) {
+		t.Errorf("expected the other fields to be kept, got %s", content)
+	}
+}
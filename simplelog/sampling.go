@@ -0,0 +1,87 @@
+package simplelog
+
+import (
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/phuthien0308/ordering-base/simplelog/tags"
+	"go.uber.org/zap/zapcore"
+)
+
+// siteSampler limits entries per call site: every tick the first entries are
+// logged, then only every thereafter-th one (none when thereafter is 0).
+type siteSampler struct {
+	first      uint64
+	thereafter uint64
+	tick       time.Duration
+	now        func() time.Time
+	sites      sync.Map // program counter -> *siteCounter
+}
+
+type siteCounter struct {
+	mutex   sync.Mutex
+	start   time.Time
+	count   uint64
+	dropped uint64
+}
+
+// allow reports whether the entry is logged and, if so, how many entries of
+// the call site were dropped since the previous one.
+func (s *siteSampler) allow(site uintptr) (bool, uint64) {
+	value, _ := s.sites.LoadOrStore(site, &siteCounter{})
+	counter := value.(*siteCounter)
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+	now := s.now()
+	if now.Sub(counter.start) >= s.tick {
+		counter.start, counter.count = now, 0
+	}
+	counter.count++
+	if counter.count <= s.first || (s.thereafter > 0 && (counter.count-s.first)%s.thereafter == 0) {
+		dropped := counter.dropped
+		counter.dropped = 0
+		return true, dropped
+	}
+	counter.dropped++
+	return false, 0
+}
+
+// Sampled returns a logger that, per call site and per tick, logs the first
+// entries then every thereafter-th. The next logged entry of a call site
+// carries the number of entries dropped before it in "sampled_dropped".
+// Only Debug and Info entries are sampled, warnings and errors are always logged.
+func (logger *SimpleLogger) Sampled(first int, thereafter int, tick time.Duration) *SimpleLogger {
+	child := *logger
+	child.sampler = &siteSampler{
+		first:      uint64(max(first, 0)),
+		thereafter: uint64(max(thereafter, 0)),
+		tick:       tick,
+		now:        time.Now,
+	}
+	return &child
+}
+
+// Every returns a logger that logs at most one entry per call site every interval,
+// which suits periodic summaries on hot paths.
+func (logger *SimpleLogger) Every(interval time.Duration) *SimpleLogger {
+	return logger.Sampled(1, 0, interval)
+}
+
+// sample must be called directly by the logging method so the call site is its caller.
+func (logger *SimpleLogger) sample(level zapcore.Level, fields []tags.T) ([]tags.T, bool) {
+	if logger.sampler == nil || level >= zapcore.WarnLevel {
+		return fields, true
+	}
+	if !logger.Core().Enabled(level) {
+		return fields, false
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	ok, dropped := logger.sampler.allow(pcs[0])
+	if ok && dropped > 0 {
+		fields = append(slices.Clip(fields), tags.Uint64("sampled_dropped", dropped))
+	}
+	return fields, ok
+}
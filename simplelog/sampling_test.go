@@ -0,0 +1,66 @@
+package simplelog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSampled(t *testing.T) {
+	zapCore, observerLogs := observer.New(zap.InfoLevel)
+	logger := NewSimpleLogger(zap.New(zapCore)).Sampled(2, 3, time.Hour)
+	now := time.Now()
+	logger.sampler.now = func() time.Time { return now }
+
+	for i := 0; i < 7; i++ {
+		logger.Info(context.Background(), "hot path")
+	}
+	// a second call site has its own budget.
+	logger.Info(context.Background(), "other path")
+
+	// entries 1, 2 and 5 (first two, then every third) are kept, entry 5 reports 2 drops.
+	hot := observerLogs.FilterMessage("hot path").All()
+	if len(hot) != 3 {
+		t.Fatalf("expected 3 entries, got %v", len(hot))
+	}
+	if dropped := hot[2].ContextMap()["sampled_dropped"]; dropped != uint64(2) {
+		t.Errorf("expected 2 dropped entries reported, got %v", dropped)
+	}
+	if observerLogs.FilterMessage("other path").Len() != 1 {
+		t.Error("expected the other call site to be logged")
+	}
+
+	// a new tick resets the budget.
+	now = now.Add(time.Hour)
+	logger.Info(context.Background(), "hot path")
+	if observerLogs.FilterMessage("hot path").Len() != 4 {
+		t.Error("expected the budget to be reset after a tick")
+	}
+}
+
+func TestEvery(t *testing.T) {
+	zapCore, observerLogs := observer.New(zap.InfoLevel)
+	logger := NewSimpleLogger(zap.New(zapCore)).Every(time.Hour)
+	for i := 0; i < 5; i++ {
+		logger.Info(context.Background(), "summary")
+		logger.Debug(context.Background(), "disabled level is not counted")
+	}
+	if observerLogs.Len() != 1 {
+		t.Errorf("expected a single entry, got %v", observerLogs.Len())
+	}
+}
+
+func TestSampledKeepsWarnings(t *testing.T) {
+	zapCore, observerLogs := observer.New(zap.InfoLevel)
+	logger := NewSimpleLogger(zap.New(zapCore)).Every(time.Hour)
+	for i := 0; i < 5; i++ {
+		logger.Warn(context.Background(), "redis unavailable")
+		logger.Error(context.Background(), "limiter failed")
+	}
+	if observerLogs.Len() != 10 {
+		t.Errorf("expected every warning and error, got %v entries", observerLogs.Len())
+	}
+}
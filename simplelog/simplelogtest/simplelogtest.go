@@ -0,0 +1,155 @@
+// Package simplelogtest builds SimpleLoggers that record entries in memory so
+// tests can assert on what was logged.
+package simplelogtest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/phuthien0308/ordering-base/simplelog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// Entries is a list of recorded entries that can be narrowed down with filters.
+type Entries []observer.LoggedEntry
+
+// Recorder holds every entry logged at debug level and above.
+type Recorder struct {
+	t              testing.TB
+	logs           *observer.ObservedLogs
+	mutex          sync.Mutex
+	expectedErrors []string
+}
+
+// New returns a logger backed by a Recorder.
+func New(t testing.TB, opts ...simplelog.Option) (*simplelog.SimpleLogger, *Recorder) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return simplelog.NewSimpleLogger(zap.New(core), opts...), &Recorder{t: t, logs: logs}
+}
+
+func (r *Recorder) All() Entries {
+	return r.logs.All()
+}
+
+func (r *Recorder) Len() int {
+	return r.logs.Len()
+}
+
+// Reset removes every recorded entry.
+func (r *Recorder) Reset() {
+	r.logs.TakeAll()
+}
+
+func (e Entries) filter(keep func(observer.LoggedEntry) bool) Entries {
+	var filtered Entries
+	for _, entry := range e {
+		if keep(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// Level keeps the entries logged exactly at level.
+func (e Entries) Level(level zapcore.Level) Entries {
+	return e.filter(func(entry observer.LoggedEntry) bool { return entry.Level == level })
+}
+
+// Message keeps the entries with exactly this message.
+func (e Entries) Message(msg string) Entries {
+	return e.filter(func(entry observer.LoggedEntry) bool { return entry.Message == msg })
+}
+
+// MessageContains keeps the entries whose message contains substr.
+func (e Entries) MessageContains(substr string) Entries {
+	return e.filter(func(entry observer.LoggedEntry) bool { return strings.Contains(entry.Message, substr) })
+}
+
+// Field keeps the entries carrying key with value, compared on the encoded
+// value so tags.String("k", "v") matches Field("k", "v").
+func (e Entries) Field(key string, value any) Entries {
+	return e.filter(func(entry observer.LoggedEntry) bool {
+		got, ok := entry.ContextMap()[key]
+		return ok && fmt.Sprint(got) == fmt.Sprint(value)
+	})
+}
+
+// HasField keeps the entries carrying key, whatever the value.
+func (e Entries) HasField(key string) Entries {
+	return e.filter(func(entry observer.LoggedEntry) bool {
+		_, ok := entry.ContextMap()[key]
+		return ok
+	})
+}
+
+// Messages returns the messages in logging order, handy in failure output.
+func (e Entries) Messages() []string {
+	messages := make([]string, len(e))
+	for i, entry := range e {
+		messages[i] = entry.Message
+	}
+	return messages
+}
+
+// AssertLogged fails the test unless at least one entry has msg.
+func (r *Recorder) AssertLogged(msg string) Entries {
+	r.t.Helper()
+	entries := r.All().Message(msg)
+	if len(entries) == 0 {
+		r.t.Errorf("expected an entry %q, got %q", msg, r.All().Messages())
+	}
+	return entries
+}
+
+// AssertContextField fails the test unless every recorded entry carries key
+// with value, e.g. the request-id set by the gRPC interceptor.
+func (r *Recorder) AssertContextField(key string, value any) {
+	r.t.Helper()
+	all := r.All()
+	if len(all) == 0 {
+		r.t.Errorf("expected entries carrying %s=%v, nothing was logged", key, value)
+		return
+	}
+	for _, entry := range all {
+		if got, ok := entry.ContextMap()[key]; !ok || fmt.Sprint(got) != fmt.Sprint(value) {
+			r.t.Errorf("entry %q: expected %s=%v, got %v", entry.Message, key, value, entry.ContextMap())
+		}
+	}
+}
+
+// ExpectError allows an Error (or higher) entry whose message contains substr.
+func (r *Recorder) ExpectError(substr string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.expectedErrors = append(r.expectedErrors, substr)
+}
+
+// FailOnError fails the test at cleanup if an Error or higher entry was
+// logged that no ExpectError call allowed.
+func (r *Recorder) FailOnError() {
+	r.t.Helper()
+	r.t.Cleanup(func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		for _, entry := range r.All() {
+			if entry.Level < zapcore.ErrorLevel || r.expected(entry.Message) {
+				continue
+			}
+			r.t.Errorf("unexpected %s entry %q with fields %v", entry.Level.CapitalString(), entry.Message,
+				entry.ContextMap())
+		}
+	})
+}
+
+func (r *Recorder) expected(msg string) bool {
+	for _, substr := range r.expectedErrors {
+		if strings.Contains(msg, substr) {
+			return true
+		}
+	}
+	return false
+}
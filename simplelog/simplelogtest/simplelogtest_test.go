@@ -0,0 +1,74 @@
+package simplelogtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/phuthien0308/ordering-base/simplelog"
+	"github.com/phuthien0308/ordering-base/simplelog/tags"
+	"go.uber.org/zap/zapcore"
+)
+
+// fakeT records failures instead of failing the real test.
+type fakeT struct {
+	testing.TB
+	failures []string
+	cleanups []func()
+}
+
+func (f *fakeT) Helper()           {}
+func (f *fakeT) Cleanup(fn func()) { f.cleanups = append(f.cleanups, fn) }
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func TestFilters(t *testing.T) {
+	logger, recorder := New(t)
+	ctx := simplelog.With(context.Background(), tags.String("request-id", "r-1"))
+	logger.Debug(ctx, "pulling addresses")
+	logger.Info(ctx, "grpc request", tags.String("grpc.method", "/product.ProductService/CreateProduct"))
+	logger.Warn(ctx, "slow grpc request", tags.Int("duration_ms", 900))
+
+	if n := len(recorder.All().Level(zapcore.InfoLevel)); n != 1 {
+		t.Errorf("expected 1 info entry, got %v", n)
+	}
+	if n := len(recorder.All().MessageContains("grpc request")); n != 2 {
+		t.Errorf("expected 2 grpc entries, got %v", n)
+	}
+	if n := len(recorder.All().Field("duration_ms", 900).Level(zapcore.WarnLevel)); n != 1 {
+		t.Errorf("expected 1 slow entry, got %v", n)
+	}
+	if n := len(recorder.All().HasField("grpc.method")); n != 1 {
+		t.Errorf("expected 1 entry with grpc.method, got %v", n)
+	}
+	recorder.AssertLogged("grpc request")
+	recorder.AssertContextField("request-id", "r-1")
+
+	recorder.Reset()
+	if recorder.Len() != 0 {
+		t.Error("expected no entries after Reset")
+	}
+}
+
+func TestAssertionsFail(t *testing.T) {
+	fake := &fakeT{}
+	logger, recorder := New(fake)
+	recorder.FailOnError()
+	recorder.ExpectError("can not pull")
+
+	logger.Info(context.Background(), "without request id")
+	logger.Error(context.Background(), "can not pull addresses", tags.Error(errors.New("timeout")))
+	logger.Error(context.Background(), "can not update state")
+
+	recorder.AssertLogged("never logged")
+	recorder.AssertContextField("request-id", "r-1")
+	for _, cleanup := range fake.cleanups {
+		cleanup()
+	}
+	// one missing message, three entries without request-id and one unexpected error.
+	if len(fake.failures) != 5 {
+		t.Errorf("expected 5 failures, got %q", fake.failures)
+	}
+}
@@ -0,0 +1,151 @@
+package simplelog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"slices"
+
+	"github.com/phuthien0308/ordering-base/simplelog/tags"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slogHandler sends slog records through a SimpleLogger, so they get the
+// context fields, the trace correlation and the logger's zap pipeline.
+type slogHandler struct {
+	logger *SimpleLogger
+	// fields from WithAttrs and WithGroup, groups are zap namespaces.
+	fields []tags.T
+}
+
+// NewSlogHandler returns a slog.Handler writing to logger:
+//
+//	slog.SetDefault(slog.New(simplelog.NewSlogHandler(simplelog.L())))
+func NewSlogHandler(logger *SimpleLogger) slog.Handler {
+	return &slogHandler{logger: logger}
+}
+
+func zapLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case level >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case level >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	}
+	return zapcore.DebugLevel
+}
+
+func slogLevel(level zapcore.Level) slog.Level {
+	switch {
+	case level >= zapcore.ErrorLevel:
+		return slog.LevelError
+	case level == zapcore.WarnLevel:
+		return slog.LevelWarn
+	case level == zapcore.InfoLevel:
+		return slog.LevelInfo
+	}
+	return slog.LevelDebug
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Core().Enabled(zapLevel(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	level := zapLevel(record.Level)
+	checked := h.logger.Check(level, record.Message)
+	if checked == nil {
+		return nil
+	}
+	checked.Time = record.Time
+	if checked.Caller.Defined && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		checked.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+		checked.Caller.Function = frame.Function
+	}
+	fields := slices.Clip(h.fields)
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = append(fields, tags.FromAttr(attr))
+		return true
+	})
+	checked.Write(h.logger.withContext(ctx, fields)...)
+	if level >= zapcore.WarnLevel {
+		h.logger.recordSpanEvent(ctx, level, record.Message, fields)
+	}
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := slices.Clip(h.fields)
+	for _, attr := range attrs {
+		fields = append(fields, tags.FromAttr(attr))
+	}
+	return &slogHandler{logger: h.logger, fields: fields}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{logger: h.logger, fields: append(slices.Clip(h.fields), zap.Namespace(name))}
+}
+
+// slogCore is a zapcore.Core writing to a slog.Handler.
+type slogCore struct {
+	handler slog.Handler
+}
+
+// FromSlogHandler returns a SimpleLogger whose entries are handled by handler.
+func FromSlogHandler(handler slog.Handler, opts ...Option) *SimpleLogger {
+	return NewSimpleLogger(zap.New(&slogCore{handler: handler}, zap.AddCaller(), zap.AddCallerSkip(1)), opts...)
+}
+
+func (c *slogCore) Enabled(level zapcore.Level) bool {
+	return c.handler.Enabled(context.Background(), slogLevel(level))
+}
+
+func (c *slogCore) With(fields []zapcore.Field) zapcore.Core {
+	handler := c.handler
+	var attrs []slog.Attr
+	for _, field := range fields {
+		if field.Type == zapcore.NamespaceType {
+			handler = handler.WithAttrs(attrs).WithGroup(field.Key)
+			attrs = nil
+			continue
+		}
+		attrs = append(attrs, tags.Attr(field))
+	}
+	return &slogCore{handler: handler.WithAttrs(attrs)}
+}
+
+func (c *slogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *slogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	record := slog.NewRecord(entry.Time, slogLevel(entry.Level), entry.Message, entry.Caller.PC)
+	record.AddAttrs(attrsOf(fields)...)
+	return c.handler.Handle(context.Background(), record)
+}
+
+// attrsOf nests the fields following a namespace into a group, as zap does.
+func attrsOf(fields []zapcore.Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for i, field := range fields {
+		if field.Type == zapcore.NamespaceType {
+			return append(attrs, slog.Attr{Key: field.Key, Value: slog.GroupValue(attrsOf(fields[i+1:])...)})
+		}
+		attrs = append(attrs, tags.Attr(field))
+	}
+	return attrs
+}
+
+func (c *slogCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,80 @@
+package simplelog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phuthien0308/ordering-base/simplelog/tags"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func jsonLogger(buffer *bytes.Buffer) *SimpleLogger {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = ""
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(buffer), zapcore.DebugLevel)
+	return NewSimpleLogger(zap.New(core))
+}
+
+func TestSlogHandlerMatchesSimpleLogger(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := jsonLogger(&buffer)
+	ctx := With(context.Background(), tags.String("request-id", "r-1"))
+	fields := []tags.T{
+		tags.String("sku", "A-1"),
+		tags.Int("quantity", 2),
+		tags.Float64("price", 9.5),
+		tags.Bool("available", true),
+		tags.Duration("latency", 1500*time.Millisecond),
+		tags.Error(errors.New("out of stock")),
+	}
+
+	logger.Warn(ctx, "reserve failed", fields...)
+	slog.New(NewSlogHandler(logger)).WarnContext(ctx, "reserve failed", tags.Attrs(fields...)...)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 2 || lines[0] != lines[1] {
+		t.Errorf("expected identical JSON, got\n%s", buffer.String())
+	}
+}
+
+func TestSlogHandlerGroups(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := slog.New(NewSlogHandler(jsonLogger(&buffer))).With("service", "product").WithGroup("request")
+	logger.Debug("search", "query", "shoes", slog.Group("page", "size", 10))
+
+	var entry map[string]any
+	if err := json.Unmarshal(buffer.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	request, _ := entry["request"].(map[string]any)
+	page, _ := request["page"].(map[string]any)
+	if entry["service"] != "product" || entry["level"] != "debug" || request["query"] != "shoes" || page["size"] != float64(10) {
+		t.Errorf("unexpected entry %v", entry)
+	}
+}
+
+func TestFromSlogHandler(t *testing.T) {
+	var buffer bytes.Buffer
+	handler := slog.NewJSONHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := FromSlogHandler(handler)
+	ctx := With(context.Background(), tags.String("request-id", "r-1"))
+
+	logger.Debug(ctx, "filtered by the handler level")
+	logger.Info(ctx, "created product", tags.String("sku", "A-1"), tags.Int("stock", 3))
+
+	var entry map[string]any
+	if err := json.Unmarshal(buffer.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a single JSON entry, got %q: %v", buffer.String(), err)
+	}
+	if entry["msg"] != "created product" || entry["level"] != "INFO" || entry["request-id"] != "r-1" ||
+		entry["sku"] != "A-1" || entry["stock"] != float64(3) {
+		t.Errorf("unexpected entry %v", entry)
+	}
+}
@@ -0,0 +1,127 @@
+package tags
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Err logs err under "error" with its wrapped chain, the stack trace of the first
+// error carrying one and, for gRPC errors, the status code and details.
+func Err(err error) T {
+	return NamedErr("error", err)
+}
+
+// NamedErr is Err under another key. A nil pointer in a non-nil error, such as
+// a nil *MyError, is logged as "<nil>".
+func NamedErr(key string, err error) T {
+	if err == nil {
+		return zap.Skip()
+	}
+	if isNilPointer(err) {
+		return zap.String(key, "<nil>")
+	}
+	return zap.Object(key, errObject{err: err})
+}
+
+func isNilPointer(err error) bool {
+	v := reflect.ValueOf(err)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+type errObject struct {
+	err error
+}
+
+// MarshalLogObject recovers from a panicking Error or Unwrap method the way zap
+// does for its error fields.
+func (e errObject) MarshalLogObject(enc zapcore.ObjectEncoder) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("PANIC=%v", r)
+		}
+	}()
+	enc.AddString("message", e.err.Error())
+	chain := errChain(e.err)
+	if len(chain) > 1 {
+		enc.AddArray("chain", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, err := range chain {
+				arr.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+					enc.AddString("type", fmt.Sprintf("%T", err))
+					enc.AddString("message", err.Error())
+					return nil
+				}))
+			}
+			return nil
+		}))
+	}
+	for _, err := range chain {
+		if stack := stackTrace(err); stack != "" {
+			enc.AddString("stack", stack)
+			break
+		}
+	}
+	if st, ok := status.FromError(e.err); ok {
+		enc.AddString("grpcCode", st.Code().String())
+		if details := st.Details(); len(details) > 0 {
+			enc.AddArray("grpcDetails", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+				for _, detail := range details {
+					if msg, ok := detail.(proto.Message); ok {
+						arr.AppendObject(protoObject{msg: msg, options: newProtoOptions(nil)})
+					} else {
+						arr.AppendString(fmt.Sprint(detail))
+					}
+				}
+				return nil
+			}))
+		}
+	}
+	return nil
+}
+
+// errChain walks Unwrap depth first, errors.Join branches included.
+func errChain(err error) []error {
+	var chain []error
+	var walk func(error)
+	walk = func(err error) {
+		for err != nil && !isNilPointer(err) {
+			chain = append(chain, err)
+			switch wrapped := err.(type) {
+			case interface{ Unwrap() []error }:
+				for _, branch := range wrapped.Unwrap() {
+					walk(branch)
+				}
+				return
+			case interface{ Unwrap() error }:
+				err = wrapped.Unwrap()
+			default:
+				return
+			}
+		}
+	}
+	walk(err)
+	return chain
+}
+
+// stackTrace supports errors exposing Stack() []byte or, like github.com/pkg/errors,
+// a StackTrace() method whose result prints the frames with %+v. A panicking
+// method logs no stack.
+func stackTrace(err error) (stack string) {
+	defer func() {
+		if recover() != nil {
+			stack = ""
+		}
+	}()
+	if stacker, ok := err.(interface{ Stack() []byte }); ok {
+		return string(stacker.Stack())
+	}
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return ""
+	}
+	return fmt.Sprintf("%+v", method.Call(nil)[0].Interface())
+}
@@ -0,0 +1,135 @@
+package tags
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultProtoMaxSize is the encoded size above which Proto logs a truncated payload.
+const DefaultProtoMaxSize = 4096
+
+const maskedValue = "[MASKED]"
+
+type protoOptions struct {
+	masks   [][]string
+	maxSize int
+}
+
+type ProtoOption func(*protoOptions)
+
+// WithMask replaces the value of the fields at paths by a placeholder. Paths use
+// the proto field names separated by dots, e.g. "card.number"; repeated fields
+// apply the rest of the path to every element.
+func WithMask(paths ...string) ProtoOption {
+	return func(o *protoOptions) {
+		for _, path := range paths {
+			o.masks = append(o.masks, strings.Split(path, "."))
+		}
+	}
+}
+
+// WithMaxSize overrides DefaultProtoMaxSize, 0 disables the limit.
+func WithMaxSize(bytes int) ProtoOption {
+	return func(o *protoOptions) {
+		o.maxSize = bytes
+	}
+}
+
+func newProtoOptions(opts []ProtoOption) protoOptions {
+	options := protoOptions{maxSize: DefaultProtoMaxSize}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// Proto logs msg as its protojson encoding with the proto field names:
+//
+//	logger.Info(ctx, "creating product", tags.Proto("request", req, tags.WithMask("price")))
+//
+// A payload larger than the size limit is logged as a truncated string with
+// "truncated" and "size" fields instead, a message that protojson does not
+// encode as an object, such as a Duration, as a "value" string.
+func Proto(key string, msg proto.Message, opts ...ProtoOption) T {
+	if msg == nil || !msg.ProtoReflect().IsValid() {
+		return zap.Reflect(key, nil)
+	}
+	return zap.Object(key, protoObject{msg: msg, options: newProtoOptions(opts)})
+}
+
+type protoObject struct {
+	msg     proto.Message
+	options protoOptions
+}
+
+func (p protoObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	content, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(p.msg)
+	if err != nil {
+		return err
+	}
+	var decoded any
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		return err
+	}
+	fields, isObject := decoded.(map[string]any)
+	if isObject && len(p.options.masks) > 0 {
+		for _, path := range p.options.masks {
+			mask(fields, path)
+		}
+		if content, err = json.Marshal(fields); err != nil {
+			return err
+		}
+	}
+	if p.options.maxSize > 0 && len(content) > p.options.maxSize {
+		enc.AddBool("truncated", true)
+		enc.AddInt("size", len(content))
+		enc.AddString("payload", strings.ToValidUTF8(string(content[:p.options.maxSize]), ""))
+		return nil
+	}
+	if !isObject {
+		// well-known types such as Duration, Timestamp or the wrappers are encoded
+		// as a JSON string or number.
+		if value, ok := decoded.(string); ok {
+			enc.AddString("value", value)
+		} else {
+			enc.AddString("value", string(content))
+		}
+		return nil
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := enc.AddReflected(key, fields[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mask(value any, path []string) {
+	switch v := value.(type) {
+	case map[string]any:
+		field, ok := v[path[0]]
+		if !ok {
+			return
+		}
+		if len(path) == 1 {
+			v[path[0]] = maskedValue
+			return
+		}
+		mask(field, path[1:])
+	case []any:
+		for _, element := range v {
+			mask(element, path)
+		}
+	}
+}
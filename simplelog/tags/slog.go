@@ -0,0 +1,109 @@
+package tags
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Attrs converts fields for the variadic arguments of slog.Logger methods:
+//
+//	slog.Info("created", tags.Attrs(tags.String("sku", sku))...)
+func Attrs(fields ...T) []any {
+	attrs := make([]any, 0, len(fields))
+	for _, field := range fields {
+		attrs = append(attrs, Attr(field))
+	}
+	return attrs
+}
+
+// Attr converts a field to its slog equivalent. Namespace fields cannot be
+// represented by a single attribute and become empty groups.
+func Attr(field T) slog.Attr {
+	switch field.Type {
+	case zapcore.StringType:
+		return slog.String(field.Key, field.String)
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+		return slog.Int64(field.Key, field.Integer)
+	case zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType:
+		return slog.Uint64(field.Key, uint64(field.Integer))
+	case zapcore.Float64Type:
+		return slog.Float64(field.Key, math.Float64frombits(uint64(field.Integer)))
+	case zapcore.Float32Type:
+		return slog.Float64(field.Key, float64(math.Float32frombits(uint32(field.Integer))))
+	case zapcore.BoolType:
+		return slog.Bool(field.Key, field.Integer == 1)
+	case zapcore.DurationType:
+		return slog.Duration(field.Key, time.Duration(field.Integer))
+	case zapcore.TimeType:
+		t := time.Unix(0, field.Integer)
+		if location, ok := field.Interface.(*time.Location); ok {
+			t = t.In(location)
+		}
+		return slog.Time(field.Key, t)
+	case zapcore.TimeFullType:
+		return slog.Time(field.Key, field.Interface.(time.Time))
+	case zapcore.ErrorType, zapcore.ReflectType:
+		return slog.Any(field.Key, field.Interface)
+	case zapcore.StringerType:
+		return slog.String(field.Key, field.Interface.(fmt.Stringer).String())
+	case zapcore.NamespaceType:
+		return slog.Group(field.Key)
+	case zapcore.SkipType:
+		return slog.Attr{}
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	field.AddTo(enc)
+	return slog.Any(field.Key, enc.Fields[field.Key])
+}
+
+// FromAttr converts a slog attribute to the field the tags helpers would build,
+// so both APIs go through the same encoder and produce the same JSON.
+func FromAttr(attr slog.Attr) T {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return zap.String(attr.Key, value.String())
+	case slog.KindInt64:
+		return zap.Int64(attr.Key, value.Int64())
+	case slog.KindUint64:
+		return zap.Uint64(attr.Key, value.Uint64())
+	case slog.KindFloat64:
+		return zap.Float64(attr.Key, value.Float64())
+	case slog.KindBool:
+		return zap.Bool(attr.Key, value.Bool())
+	case slog.KindDuration:
+		return zap.Duration(attr.Key, value.Duration())
+	case slog.KindTime:
+		return zap.Time(attr.Key, value.Time())
+	case slog.KindGroup:
+		group := attrGroup(value.Group())
+		if len(group) == 0 {
+			return zap.Skip()
+		}
+		if attr.Key == "" {
+			return zap.Inline(group)
+		}
+		return zap.Object(attr.Key, group)
+	}
+	if attr.Key == "" && value.Any() == nil {
+		return zap.Skip()
+	}
+	if err, ok := value.Any().(error); ok {
+		return zap.NamedError(attr.Key, err)
+	}
+	return zap.Any(attr.Key, value.Any())
+}
+
+type attrGroup []slog.Attr
+
+func (g attrGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, attr := range g {
+		FromAttr(attr).AddTo(enc)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package tags
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type stackError struct {
+	error
+}
+
+type stack []string
+
+func (s stack) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, strings.Join(s, "\n"))
+}
+
+func (e stackError) StackTrace() stack {
+	return stack{"main.handler", "\t/app/main.go:42"}
+}
+
+func (e stackError) Unwrap() error {
+	return e.error
+}
+
+func encode(t *testing.T, field T) map[string]any {
+	t.Helper()
+	enc := zapcore.NewMapObjectEncoder()
+	field.AddTo(enc)
+	object, ok := enc.Fields[field.Key].(map[string]any)
+	if !ok {
+		t.Fatalf("expected an object under %s, got %v", field.Key, enc.Fields)
+	}
+	return object
+}
+
+func TestErr(t *testing.T) {
+	st, err := status.New(codes.NotFound, "product not found").
+		WithDetails(&errdetails.ResourceInfo{ResourceType: "product", ResourceName: "A-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped := fmt.Errorf("get product: %w", stackError{st.Err()})
+
+	object := encode(t, Err(wrapped))
+	if object["message"] != wrapped.Error() || object["grpcCode"] != "NotFound" {
+		t.Errorf("unexpected error object %v", object)
+	}
+	if chain := object["chain"].([]any); len(chain) != 3 {
+		t.Errorf("expected the 3 errors of the chain, got %v", chain)
+	}
+	if object["stack"] != "main.handler\n\t/app/main.go:42" {
+		t.Errorf("unexpected stack %q", object["stack"])
+	}
+	details := object["grpcDetails"].([]any)
+	if detail := details[0].(map[string]any); detail["resource_name"] != "A-1" {
+		t.Errorf("unexpected details %v", details)
+	}
+
+	object = encode(t, NamedErr("cause", errors.Join(errors.New("first"), errors.New("second"))))
+	if len(object["chain"].([]any)) != 3 || object["grpcCode"] != nil || object["stack"] != nil {
+		t.Errorf("unexpected joined error object %v", object)
+	}
+	if field := Err(nil); field.Type != zapcore.SkipType {
+		t.Errorf("expected a nil error to be skipped, got %v", field)
+	}
+
+	var typedNil *pointerError
+	if field := Err(typedNil); field.Type != zapcore.StringType || field.String != "<nil>" {
+		t.Errorf("expected a nil pointer error to be logged as <nil>, got %v", field)
+	}
+	object = encode(t, Err(fmt.Errorf("wrap: %w", &pointerError{stackPanics: true})))
+	if len(object["chain"].([]any)) != 2 || object["stack"] != nil {
+		t.Errorf("expected a panicking StackTrace to be skipped, got %v", object)
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	Err(&pointerError{errorPanics: true}).AddTo(enc)
+	if enc.Fields["errorError"] != "PANIC=boom" {
+		t.Errorf("expected a panicking Error to be reported, got %v", enc.Fields)
+	}
+}
+
+type pointerError struct {
+	errorPanics bool
+	stackPanics bool
+}
+
+func (e *pointerError) Error() string {
+	if e.errorPanics {
+		panic("boom")
+	}
+	return "pointer error"
+}
+
+func (e *pointerError) StackTrace() stack {
+	if e.stackPanics {
+		panic("boom")
+	}
+	return nil
+}
+
+func TestProto(t *testing.T) {
+	msg := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("product.proto"),
+		Package: proto.String("product"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("CreateProductRequest")},
+			{Name: proto.String("CreateProductResponse")},
+		},
+	}
+
+	object := encode(t, Proto("request", msg, WithMask("package", "message_type.name", "syntax")))
+	if object["name"] != "product.proto" || object["package"] != maskedValue {
+		t.Errorf("unexpected proto object %v", object)
+	}
+	for _, messageType := range object["message_type"].([]any) {
+		if name := messageType.(map[string]any)["name"]; name != maskedValue {
+			t.Errorf("expected the message names to be masked, got %v", name)
+		}
+	}
+	if _, ok := object["syntax"]; ok {
+		t.Error("masking must not add unset fields")
+	}
+
+	object = encode(t, Proto("request", msg, WithMaxSize(20)))
+	if object["truncated"] != true || len(object["payload"].(string)) != 20 || object["size"].(int) <= 20 {
+		t.Errorf("unexpected truncated object %v", object)
+	}
+
+	if object := encode(t, Proto("timeout", durationpb.New(1500*time.Millisecond))); object["value"] != "1.500s" {
+		t.Errorf("expected a duration as a string value, got %v", object)
+	}
+	if object := encode(t, Proto("count", wrapperspb.Int32(3))); object["value"] != "3" {
+		t.Errorf("expected a wrapper as a string value, got %v", object)
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	Proto("request", (*descriptorpb.FileDescriptorProto)(nil)).AddTo(enc)
+	if value, ok := enc.Fields["request"]; !ok || value != nil {
+		t.Errorf("expected a nil message to be logged as null, got %v", enc.Fields)
+	}
+}
@@ -0,0 +1,66 @@
+package simplelog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/phuthien0308/ordering-base/simplelog/tags"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+// traceFields correlates log entries with the OpenTelemetry span in ctx, if any.
+func traceFields(ctx context.Context) []tags.T {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return nil
+	}
+	return []tags.T{
+		tags.String("trace_id", spanContext.TraceID().String()),
+		tags.String("span_id", spanContext.SpanID().String()),
+		tags.Bool("trace_sampled", spanContext.IsSampled()),
+	}
+}
+
+// baggageFields are the baggage members of ctx the logger was configured with.
+func (logger *SimpleLogger) baggageFields(ctx context.Context) []tags.T {
+	if len(logger.baggageKeys) == 0 {
+		return nil
+	}
+	members := baggage.FromContext(ctx)
+	var fields []tags.T
+	for _, key := range logger.baggageKeys {
+		if value := members.Member(key).Value(); value != "" {
+			fields = append(fields, tags.String(key, value))
+		}
+	}
+	return fields
+}
+
+func (logger *SimpleLogger) recordSpanEvent(ctx context.Context, level zapcore.Level, msg string, fields []tags.T) {
+	if !logger.spanEvents || !logger.Core().Enabled(level) {
+		return
+	}
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("log.severity", level.CapitalString()),
+		attribute.String("log.message", msg),
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	for key, value := range enc.Fields {
+		attrs = append(attrs, attribute.String(key, fmt.Sprint(value)))
+	}
+	span.AddEvent("log", trace.WithAttributes(attrs...))
+	if level >= zapcore.ErrorLevel {
+		span.SetStatus(codes.Error, msg)
+	}
+}
@@ -0,0 +1,100 @@
+package simplelog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/phuthien0308/ordering-base/simplelog/tags"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTraceCorrelation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("simplelog").Start(context.Background(), "CreateProduct")
+
+	zapCore, observerLogs := observer.New(zap.InfoLevel)
+	logger := NewSimpleLogger(zap.New(zapCore), WithSpanEvents())
+	logger.Info(ctx, "creating product")
+	logger.Error(ctx, "can not create product", tags.Error(errors.New("duplicated sku")))
+	span.End()
+
+	fields := observerLogs.All()[0].ContextMap()
+	if fields["trace_id"] != span.SpanContext().TraceID().String() ||
+		fields["span_id"] != span.SpanContext().SpanID().String() || fields["trace_sampled"] != true {
+		t.Errorf("missing trace correlation fields, got %v", fields)
+	}
+
+	ended := recorder.Ended()[0]
+	if len(ended.Events()) != 1 || ended.Events()[0].Name != "log" {
+		t.Fatalf("expected only the error entry as span event, got %v", ended.Events())
+	}
+	if ended.Status().Code != codes.Error || ended.Status().Description != "can not create product" {
+		t.Errorf("expected error status, got %v", ended.Status())
+	}
+}
+
+func TestTraceCorrelationWithoutSpan(t *testing.T) {
+	zapCore, observerLogs := observer.New(zap.InfoLevel)
+	logger := NewSimpleLogger(zap.New(zapCore), WithSpanEvents())
+	logger.Warn(context.Background(), "no span")
+	if _, ok := observerLogs.All()[0].ContextMap()["trace_id"]; ok {
+		t.Error("trace_id should only be set when a span is present")
+	}
+}
+
+func TestTraceCorrelationReplacesFields(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("simplelog").Start(context.Background(), "CreateProduct")
+	ctx = With(ctx, tags.String("trace_id", "stale"), tags.String("span_id", "stale"))
+
+	zapCore, observerLogs := observer.New(zap.ErrorLevel)
+	logger := NewSimpleLogger(zap.New(zapCore), WithSpanEvents())
+	logger.Warn(ctx, "disabled entry")
+	logger.Error(ctx, "can not create product")
+	span.End()
+
+	entry := observerLogs.All()[0]
+	traceIDs := 0
+	for _, field := range entry.Context {
+		if field.Key == "trace_id" {
+			traceIDs++
+		}
+	}
+	if traceIDs != 1 || entry.ContextMap()["trace_id"] != span.SpanContext().TraceID().String() {
+		t.Errorf("expected one trace_id of the span, got %v", entry.Context)
+	}
+	if events := recorder.Ended()[0].Events(); len(events) != 1 {
+		t.Errorf("expected no span event for the disabled warn entry, got %v", events)
+	}
+}
+
+func TestBaggageFields(t *testing.T) {
+	tenant, _ := baggage.NewMemberRaw("tenant_id", "acme")
+	user, _ := baggage.NewMemberRaw("user_id", "u-1")
+	secret, _ := baggage.NewMemberRaw("session", "s3cr3t")
+	members, _ := baggage.New(tenant, user, secret)
+	ctx := baggage.ContextWithBaggage(context.Background(), members)
+	ctx = With(ctx, tags.String("user_id", "u-2"))
+
+	zapCore, observerLogs := observer.New(zap.InfoLevel)
+	logger := NewSimpleLogger(zap.New(zapCore), WithBaggageFields("tenant_id", "user_id", "request_id"))
+	logger.Info(ctx, "creating product")
+
+	fields := observerLogs.All()[0].ContextMap()
+	if fields["tenant_id"] != "acme" || fields["user_id"] != "u-2" {
+		t.Errorf("expected the baggage fields, got %v", fields)
+	}
+	for _, key := range []string{"session", "request_id"} {
+		if _, ok := fields[key]; ok {
+			t.Errorf("unexpected field %s in %v", key, fields)
+		}
+	}
+}
@@ -32,15 +32,14 @@ func main() {
 
 	// 2. Setup Context with parameters
 	ctx := context.Background()
-	logFields := []zap.Field{
+	ctx = simplelog.With(ctx,
 		tags.String("file", *filePtr),
 		tags.String("struct", *structPtr),
 		tags.String("table", *tablePtr),
 		tags.String("pkg", *pkgPtr),
 		tags.String("out", *outPtr),
 		tags.String("dialect", *dialectPtr),
-	}
-	ctx = context.WithValue(ctx, simplelog.SimpleLogKeyCtx, logFields)
+	)
 
 	// 3. Validate required arguments
 	if *structPtr == "" {
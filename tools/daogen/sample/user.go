@@ -6,8 +6,8 @@ import "time"
 type User struct {
 	ID        int64     `sql-col:"id" sql-identifier:"true"`
 	Username  string    `sql-col:"username" sql-insert:"true"`
-	Email     string    `sql-col:"email" sql-insert:"true" sql-update:"true"`
-	Password  string    `sql-col:"password" sql-insert:"true" sql-update:"true"`
+	Email     string    `sql-col:"email" sql-insert:"true" sql-update:"true" redact:"hash"`
+	Password  string    `sql-col:"password" sql-insert:"true" sql-update:"true" redact:"drop"`
 	CreatedAt time.Time `sql-col:"created_at" sql-skip:"true"`
 	Internal  string    `sql-skip:"true"`
 }
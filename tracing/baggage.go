@@ -0,0 +1,165 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// The baggage keys of the typed helpers, also used as log field and span attribute names.
+const (
+	BaggageTenantID  = "tenant_id"
+	BaggageUserID    = "user_id"
+	BaggageRequestID = "request_id"
+)
+
+// DefaultBaggageKeys are the keys of the typed helpers.
+var DefaultBaggageKeys = []string{BaggageTenantID, BaggageUserID, BaggageRequestID}
+
+// SetBaggage returns a copy of ctx whose baggage carries key=value, replacing
+// a previous value of key. The value is percent-encoded on the wire.
+func SetBaggage(ctx context.Context, key string, value string) (context.Context, error) {
+	member, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid baggage member %q: %w", key, err)
+	}
+	members, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx, fmt.Errorf("can not set baggage member %q: %w", key, err)
+	}
+	return baggage.ContextWithBaggage(ctx, members), nil
+}
+
+// WithTenantID puts the tenant id in the baggage of ctx.
+func WithTenantID(ctx context.Context, id string) (context.Context, error) {
+	return SetBaggage(ctx, BaggageTenantID, id)
+}
+
+// TenantID returns the tenant id in the baggage of ctx, empty when absent. The
+// value is set by the caller and not authenticated, do not authorize with it.
+func TenantID(ctx context.Context) string {
+	return baggage.FromContext(ctx).Member(BaggageTenantID).Value()
+}
+
+// WithUserID puts the user id in the baggage of ctx.
+func WithUserID(ctx context.Context, id string) (context.Context, error) {
+	return SetBaggage(ctx, BaggageUserID, id)
+}
+
+// UserID returns the user id in the baggage of ctx, empty when absent. Like
+// TenantID, it is not authenticated.
+func UserID(ctx context.Context) string {
+	return baggage.FromContext(ctx).Member(BaggageUserID).Value()
+}
+
+// WithRequestID puts the request id in the baggage of ctx.
+func WithRequestID(ctx context.Context, id string) (context.Context, error) {
+	return SetBaggage(ctx, BaggageRequestID, id)
+}
+
+// RequestID returns the request id in the baggage of ctx, empty when absent.
+func RequestID(ctx context.Context) string {
+	return baggage.FromContext(ctx).Member(BaggageRequestID).Value()
+}
+
+// BaggageLimits bound the baggage crossing a service boundary, baggage is
+// forwarded to every downstream call so a caller can not be trusted with it.
+type BaggageLimits struct {
+	// AllowedKeys lists the members kept, empty keeps every member.
+	AllowedKeys []string
+	// MaxMembers is the number of members kept, zero means no limit.
+	MaxMembers int
+	// MaxBytes is the size of the encoded baggage header, zero means no limit.
+	MaxBytes int
+}
+
+// DefaultBaggageLimits keeps the DefaultBaggageKeys within 1KB.
+func DefaultBaggageLimits() BaggageLimits {
+	return BaggageLimits{AllowedKeys: DefaultBaggageKeys, MaxMembers: len(DefaultBaggageKeys), MaxBytes: 1024}
+}
+
+// LimitBaggage returns a copy of ctx whose baggage only has the allowed members,
+// in the order of AllowedKeys (or by key), until MaxMembers or MaxBytes is reached.
+func LimitBaggage(ctx context.Context, limits BaggageLimits) context.Context {
+	members := baggage.FromContext(ctx).Members()
+	if len(members) == 0 {
+		return ctx
+	}
+	slices.SortFunc(members, func(a, b baggage.Member) int {
+		return rank(limits.AllowedKeys, a.Key(), b.Key())
+	})
+	var kept []baggage.Member
+	size := 0
+	for _, member := range members {
+		if len(limits.AllowedKeys) > 0 && !slices.Contains(limits.AllowedKeys, member.Key()) {
+			continue
+		}
+		if limits.MaxMembers > 0 && len(kept) == limits.MaxMembers {
+			break
+		}
+		// members are joined with a comma.
+		memberSize := len(member.String())
+		if len(kept) > 0 {
+			memberSize++
+		}
+		if limits.MaxBytes > 0 && size+memberSize > limits.MaxBytes {
+			continue
+		}
+		size += memberSize
+		kept = append(kept, member)
+	}
+	limited, err := baggage.New(kept...)
+	if err != nil {
+		return baggage.ContextWithoutBaggage(ctx)
+	}
+	return baggage.ContextWithBaggage(ctx, limited)
+}
+
+// rank orders a and b by their position in keys, then by name.
+func rank(keys []string, a string, b string) int {
+	i, j := slices.Index(keys, a), slices.Index(keys, b)
+	if i != j {
+		return i - j
+	}
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// BaggageSpanProcessor copies baggage members of the parent context to the
+// attributes of every span started, so spans can be searched by tenant or user.
+type BaggageSpanProcessor struct {
+	keys []string
+}
+
+// NewBaggageSpanProcessor copies the members under keys, DefaultBaggageKeys
+// when none is given.
+func NewBaggageSpanProcessor(keys ...string) *BaggageSpanProcessor {
+	if len(keys) == 0 {
+		keys = DefaultBaggageKeys
+	}
+	return &BaggageSpanProcessor{keys: keys}
+}
+
+func (p *BaggageSpanProcessor) OnStart(parent context.Context, span sdktrace.ReadWriteSpan) {
+	members := baggage.FromContext(parent)
+	for _, key := range p.keys {
+		if value := members.Member(key).Value(); value != "" {
+			span.SetAttributes(attribute.String(key, value))
+		}
+	}
+}
+
+func (p *BaggageSpanProcessor) OnEnd(sdktrace.ReadOnlySpan) {}
+
+func (p *BaggageSpanProcessor) Shutdown(context.Context) error { return nil }
+
+func (p *BaggageSpanProcessor) ForceFlush(context.Context) error { return nil }
@@ -0,0 +1,110 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestBaggageHelpers(t *testing.T) {
+	ctx, err := WithTenantID(context.Background(), "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, _ = WithUserID(ctx, "user 1;admin")
+	ctx, _ = WithRequestID(ctx, "req-1")
+	ctx, _ = WithTenantID(ctx, "globex")
+
+	if TenantID(ctx) != "globex" || UserID(ctx) != "user 1;admin" || RequestID(ctx) != "req-1" {
+		t.Errorf("unexpected baggage %s", baggage.FromContext(ctx))
+	}
+	if TenantID(context.Background()) != "" {
+		t.Error("expected no tenant without baggage")
+	}
+	if _, err := SetBaggage(ctx, "", "value"); err == nil {
+		t.Error("expected an empty key to be rejected")
+	}
+}
+
+func TestLimitBaggage(t *testing.T) {
+	ctx, _ := WithTenantID(context.Background(), "acme")
+	ctx, _ = WithUserID(ctx, "u-1")
+	ctx, _ = WithRequestID(ctx, strings.Repeat("r", 100))
+	ctx, _ = SetBaggage(ctx, "session", "s3cr3t")
+
+	tests := []struct {
+		name     string
+		limits   BaggageLimits
+		expected string
+	}{
+		{"no limits", BaggageLimits{}, "request_id=" + strings.Repeat("r", 100) + ",session=s3cr3t,tenant_id=acme,user_id=u-1"},
+		{"allowed keys", BaggageLimits{AllowedKeys: []string{"user_id", "tenant_id"}}, "user_id=u-1,tenant_id=acme"},
+		{"max members", BaggageLimits{AllowedKeys: DefaultBaggageKeys, MaxMembers: 2}, "tenant_id=acme,user_id=u-1"},
+		{"max bytes", BaggageLimits{AllowedKeys: DefaultBaggageKeys, MaxBytes: 64}, "tenant_id=acme,user_id=u-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limited := baggage.FromContext(LimitBaggage(ctx, tt.limits))
+			for _, pair := range strings.Split(tt.expected, ",") {
+				key, value, _ := strings.Cut(pair, "=")
+				if limited.Member(key).Value() != value {
+					t.Errorf("expected %s in %s", pair, limited)
+				}
+			}
+			if limited.Len() != len(strings.Split(tt.expected, ",")) {
+				t.Errorf("expected %s, got %s", tt.expected, limited)
+			}
+		})
+	}
+}
+
+func TestBaggageSpanProcessor(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(NewBaggageSpanProcessor()), sdktrace.WithSpanProcessor(recorder))
+	ctx, _ := WithTenantID(context.Background(), "acme")
+	ctx, _ = SetBaggage(ctx, "session", "s3cr3t")
+
+	_, span := provider.Tracer("test").Start(ctx, "CreateOrder")
+	span.End()
+
+	attributes := recorder.Ended()[0].Attributes()
+	if len(attributes) != 1 || attributes[0] != attribute.String(BaggageTenantID, "acme") {
+		t.Errorf("unexpected attributes %v", attributes)
+	}
+}
+
+func TestInterceptorBaggageLimits(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/product.ProductService/CreateProduct"}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("baggage", "tenant_id=acme,role=admin"))
+
+	tests := []struct {
+		name     string
+		opts     []InterceptorOption
+		expected int
+	}{
+		{"default", nil, 1},
+		{"explicit", []InterceptorOption{WithBaggageLimits(BaggageLimits{AllowedKeys: []string{"role"}})}, 1},
+		{"disabled", []InterceptorOption{WithoutBaggageLimits()}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := UnaryServerInterceptor(append([]InterceptorOption{WithPropagator(propagation.Baggage{})}, tt.opts...)...)
+			var received baggage.Baggage
+			interceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+				received = baggage.FromContext(ctx)
+				return nil, nil
+			})
+			if received.Len() != tt.expected {
+				t.Errorf("expected %d members to cross the boundary, got %s", tt.expected, received)
+			}
+		})
+	}
+}
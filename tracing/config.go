@@ -0,0 +1,336 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/exporters/zipkin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	ExporterZipkin = "zipkin"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	// ExporterFile writes one JSON span per line to Config.FilePath.
+	ExporterFile = "file"
+	ExporterNone = "none"
+
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http/protobuf"
+
+	DefaultZipkinEndpoint = "http://localhost:9411/api/v2/spans"
+)
+
+// BatchConfig tunes the batch span processor, zero values keep the SDK defaults.
+type BatchConfig struct {
+	MaxQueueSize       int
+	MaxExportBatchSize int
+	BatchTimeout       time.Duration
+	ExportTimeout      time.Duration
+}
+
+// Config describes the exporter and provider built by Setup.
+type Config struct {
+	ServiceName string
+	// Exporter is one of the Exporter* constants.
+	Exporter string
+	// Protocol of the OTLP exporter, ProtocolGRPC or ProtocolHTTP.
+	Protocol string
+	// Endpoint is the Zipkin URL or the OTLP endpoint URL, used as is. An empty
+	// OTLP endpoint lets the exporter read OTEL_EXPORTER_OTLP_* itself.
+	Endpoint string
+	Headers  map[string]string
+	Insecure bool
+	FilePath string
+	// SamplingRatio of the new traces, children follow their parent.
+	SamplingRatio float64
+	Batch         BatchConfig
+}
+
+func DefaultConfig(serviceName string) Config {
+	return Config{
+		ServiceName:   serviceName,
+		Exporter:      ExporterZipkin,
+		Protocol:      ProtocolHTTP,
+		Endpoint:      DefaultZipkinEndpoint,
+		SamplingRatio: 1,
+	}
+}
+
+// ConfigFromEnv reads the standard OTEL_* variables on top of DefaultConfig:
+// OTEL_SERVICE_NAME, OTEL_TRACES_EXPORTER (console is an alias of stdout, file
+// reads OTEL_EXPORTER_FILE_PATH), OTEL_EXPORTER_OTLP_[TRACES_]{PROTOCOL,ENDPOINT,
+// HEADERS,INSECURE}, OTEL_EXPORTER_ZIPKIN_ENDPOINT, OTEL_TRACES_SAMPLER[_ARG] and
+// OTEL_BSP_*. The ratio samplers are always parent based.
+func ConfigFromEnv(serviceName string) (Config, error) {
+	cfg := DefaultConfig(serviceName)
+	if v, ok := os.LookupEnv("OTEL_SERVICE_NAME"); ok && v != "" {
+		cfg.ServiceName = v
+	}
+	if v, ok := os.LookupEnv("OTEL_TRACES_EXPORTER"); ok && v != "" {
+		cfg.Exporter = strings.TrimSpace(strings.Split(v, ",")[0])
+		if cfg.Exporter == "console" {
+			cfg.Exporter = ExporterStdout
+		}
+		if cfg.Exporter != ExporterZipkin {
+			cfg.Endpoint = ""
+		}
+	}
+	if v := lookupOTLP("PROTOCOL"); v != "" {
+		cfg.Protocol = v
+	}
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		cfg.Endpoint = otlpEndpoint("traces", cfg.Protocol)
+	case ExporterZipkin:
+		if v := os.Getenv("OTEL_EXPORTER_ZIPKIN_ENDPOINT"); v != "" {
+			cfg.Endpoint = v
+		}
+	}
+	if v := lookupOTLP("HEADERS"); v != "" {
+		headers, err := parseOTLPHeaders(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Headers = headers
+	}
+	if v := lookupOTLP("INSECURE"); v != "" {
+		insecure, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid OTLP insecure %q: %w", v, err)
+		}
+		cfg.Insecure = insecure
+	}
+	cfg.FilePath = os.Getenv("OTEL_EXPORTER_FILE_PATH")
+	if err := samplerFromEnv(&cfg); err != nil {
+		return cfg, err
+	}
+	for name, target := range map[string]*int{
+		"OTEL_BSP_MAX_QUEUE_SIZE":        &cfg.Batch.MaxQueueSize,
+		"OTEL_BSP_MAX_EXPORT_BATCH_SIZE": &cfg.Batch.MaxExportBatchSize,
+	} {
+		if v, ok := os.LookupEnv(name); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid %s %q: %w", name, v, err)
+			}
+			*target = n
+		}
+	}
+	for name, target := range map[string]*time.Duration{
+		"OTEL_BSP_SCHEDULE_DELAY": &cfg.Batch.BatchTimeout,
+		"OTEL_BSP_EXPORT_TIMEOUT": &cfg.Batch.ExportTimeout,
+	} {
+		if v, ok := os.LookupEnv(name); ok {
+			ms, err := strconv.Atoi(v)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid %s %q, expected milliseconds: %w", name, v, err)
+			}
+			*target = time.Duration(ms) * time.Millisecond
+		}
+	}
+	return cfg, nil
+}
+
+// lookupOTLP prefers the traces specific variable over the generic one.
+func lookupOTLP(name string) string {
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_" + name); v != "" {
+		return v
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_" + name)
+}
+
+// otlpEndpoint returns OTEL_EXPORTER_OTLP_<SIGNAL>_ENDPOINT as is or, over HTTP,
+// OTEL_EXPORTER_OTLP_ENDPOINT with the path of the signal, e.g. /v1/traces.
+func otlpEndpoint(signal, protocol string) string {
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_" + strings.ToUpper(signal) + "_ENDPOINT"); v != "" {
+		return v
+	}
+	v := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if v == "" || protocol == ProtocolGRPC {
+		return v
+	}
+	return strings.TrimSuffix(v, "/") + "/v1/" + signal
+}
+
+// parseOTLPHeaders parses the comma separated key=value list of
+// OTEL_EXPORTER_OTLP_HEADERS, whose values are URL encoded.
+func parseOTLPHeaders(v string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid OTLP header %q, expected key=value", pair)
+		}
+		decoded, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTLP header %q: %w", pair, err)
+		}
+		headers[strings.TrimSpace(key)] = decoded
+	}
+	return headers, nil
+}
+
+func samplerFromEnv(cfg *Config) error {
+	sampler := os.Getenv("OTEL_TRACES_SAMPLER")
+	switch sampler {
+	case "", "always_on", "parentbased_always_on":
+		cfg.SamplingRatio = 1
+	case "always_off", "parentbased_always_off":
+		cfg.SamplingRatio = 0
+	case "traceidratio", "parentbased_traceidratio":
+		cfg.SamplingRatio = 1
+		if v, ok := os.LookupEnv("OTEL_TRACES_SAMPLER_ARG"); ok {
+			ratio, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return fmt.Errorf("invalid OTEL_TRACES_SAMPLER_ARG %q: %w", v, err)
+			}
+			cfg.SamplingRatio = ratio
+		}
+	default:
+		return fmt.Errorf("unsupported OTEL_TRACES_SAMPLER %q", sampler)
+	}
+	return nil
+}
+
+func (c Config) Validate() error {
+	if c.ServiceName == "" {
+		return fmt.Errorf("service name is required")
+	}
+	switch c.Exporter {
+	case ExporterZipkin:
+		if c.Endpoint == "" {
+			return fmt.Errorf("zipkin endpoint is required")
+		}
+	case ExporterOTLP:
+		if c.Protocol != ProtocolGRPC && c.Protocol != ProtocolHTTP {
+			return fmt.Errorf("invalid OTLP protocol %q, expected grpc or http/protobuf", c.Protocol)
+		}
+	case ExporterFile:
+		if c.FilePath == "" {
+			return fmt.Errorf("file path is required by the file exporter")
+		}
+	case ExporterStdout, ExporterNone:
+	default:
+		return fmt.Errorf("invalid exporter %q", c.Exporter)
+	}
+	if c.Batch.MaxQueueSize < 0 || c.Batch.MaxExportBatchSize < 0 || c.Batch.BatchTimeout < 0 || c.Batch.ExportTimeout < 0 {
+		return fmt.Errorf("batch settings must not be negative")
+	}
+	return validateRatio(c.SamplingRatio)
+}
+
+func (c Config) batchOptions() []sdktrace.BatchSpanProcessorOption {
+	var opts []sdktrace.BatchSpanProcessorOption
+	if c.Batch.MaxQueueSize > 0 {
+		opts = append(opts, sdktrace.WithMaxQueueSize(c.Batch.MaxQueueSize))
+	}
+	if c.Batch.MaxExportBatchSize > 0 {
+		opts = append(opts, sdktrace.WithMaxExportBatchSize(c.Batch.MaxExportBatchSize))
+	}
+	if c.Batch.BatchTimeout > 0 {
+		opts = append(opts, sdktrace.WithBatchTimeout(c.Batch.BatchTimeout))
+	}
+	if c.Batch.ExportTimeout > 0 {
+		opts = append(opts, sdktrace.WithExportTimeout(c.Batch.ExportTimeout))
+	}
+	return opts
+}
+
+// NewExporter builds the exporter cfg selects. The returned close function
+// releases what the exporter shutdown does not, such as the file.
+func NewExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, func() error, error) {
+	noClose := func() error { return nil }
+	switch cfg.Exporter {
+	case ExporterZipkin:
+		exporter, err := zipkin.New(cfg.Endpoint, zipkin.WithHeaders(cfg.Headers))
+		return exporter, noClose, err
+	case ExporterOTLP:
+		if cfg.Protocol == ProtocolGRPC {
+			opts := []otlptracegrpc.Option{}
+			if cfg.Endpoint != "" {
+				opts = append(opts, otlptracegrpc.WithEndpointURL(cfg.Endpoint))
+			}
+			if len(cfg.Headers) > 0 {
+				opts = append(opts, otlptracegrpc.WithHeaders(cfg.Headers))
+			}
+			if cfg.Insecure {
+				opts = append(opts, otlptracegrpc.WithInsecure())
+			}
+			exporter, err := otlptracegrpc.New(ctx, opts...)
+			return exporter, noClose, err
+		}
+		opts := []otlptracehttp.Option{}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		return exporter, noClose, err
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		return exporter, noClose, err
+	case ExporterFile:
+		file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+		return exporter, file.Close, nil
+	case ExporterNone:
+		return &NoopExporter{}, noClose, nil
+	}
+	return nil, nil, fmt.Errorf("invalid exporter %q", cfg.Exporter)
+}
+
+// Setup builds the exporter and resource from cfg and installs the global
+// provider. The returned function shuts the provider down, flushing the spans,
+// then releases the exporter resources.
+//
+//	cfg, err := tracing.ConfigFromEnv("productservice")
+//	shutdown, err := tracing.Setup(ctx, cfg)
+//	defer shutdown(context.Background())
+func Setup(ctx context.Context, cfg Config, opts ...ProviderOption) (func(context.Context) error, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	res, err := newResource(ctx, cfg.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	exporter, closeExporter, err := NewExporter(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exporter: %w", err)
+	}
+	sampler, err := NewSampler(cfg.SamplingRatio)
+	if err != nil {
+		return nil, errors.Join(err, closeExporter())
+	}
+	opts = append([]ProviderOption{WithSampler(sampler), WithBatchOptions(cfg.batchOptions()...)}, opts...)
+	shutdown, err := InitGlobalTracer(exporter, res, opts...)
+	if err != nil {
+		return nil, errors.Join(err, closeExporter())
+	}
+	return func(ctx context.Context) error {
+		return errors.Join(shutdown(ctx), closeExporter())
+	}, nil
+}
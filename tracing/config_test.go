@@ -0,0 +1,131 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "productservice")
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4317")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://traces:4317")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=secret")
+	t.Setenv("OTEL_TRACES_SAMPLER", "parentbased_traceidratio")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+	t.Setenv("OTEL_BSP_MAX_QUEUE_SIZE", "4096")
+	t.Setenv("OTEL_BSP_SCHEDULE_DELAY", "500")
+
+	cfg, err := ConfigFromEnv("fallback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ServiceName != "productservice" || cfg.Exporter != ExporterOTLP || cfg.Protocol != ProtocolGRPC ||
+		cfg.Endpoint != "http://traces:4317" || cfg.Headers["api-key"] != "secret" || cfg.SamplingRatio != 0.25 ||
+		cfg.Batch.MaxQueueSize != 4096 || cfg.Batch.BatchTimeout != 500*time.Millisecond {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	// the generic endpoint is the base URL of every signal over HTTP.
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318/")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Basic%20dXNlcjpwYXNz, tenant = a%2Cb")
+	cfg, err = ConfigFromEnv("fallback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Endpoint != "http://collector:4318/v1/traces" || cfg.Headers["Authorization"] != "Basic dXNlcjpwYXNz" || cfg.Headers["tenant"] != "a,b" {
+		t.Errorf("unexpected endpoint %s or headers %v", cfg.Endpoint, cfg.Headers)
+	}
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=%zz")
+	if _, err := ConfigFromEnv("fallback"); err == nil {
+		t.Error("expected an invalid encoded header to be rejected")
+	}
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "")
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "console")
+	if cfg, _ := ConfigFromEnv("fallback"); cfg.Exporter != ExporterStdout {
+		t.Errorf("expected console to select stdout, got %s", cfg.Exporter)
+	}
+	t.Setenv("OTEL_TRACES_SAMPLER", "jaeger_remote")
+	if _, err := ConfigFromEnv("fallback"); err == nil {
+		t.Error("expected an unsupported sampler to be rejected")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tcs := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "service", modify: func(c *Config) { c.ServiceName = "" }},
+		{name: "exporter", modify: func(c *Config) { c.Exporter = "jaeger" }},
+		{name: "protocol", modify: func(c *Config) { c.Exporter, c.Protocol = ExporterOTLP, "thrift" }},
+		{name: "file", modify: func(c *Config) { c.Exporter = ExporterFile }},
+		{name: "ratio", modify: func(c *Config) { c.SamplingRatio = 2 }},
+		{name: "batch", modify: func(c *Config) { c.Batch.MaxQueueSize = -1 }},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := DefaultConfig("productservice")
+			tc.modify(&cfg)
+			if err := cfg.Validate(); err == nil {
+				t.Error("expected a validation error")
+			}
+		})
+	}
+}
+
+func TestSetupFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	cfg := DefaultConfig("productservice")
+	cfg.Exporter, cfg.FilePath = ExporterFile, path
+	cfg.Batch = BatchConfig{MaxExportBatchSize: 1, BatchTimeout: time.Millisecond}
+
+	shutdown, err := Setup(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracer := NewTracer("test")
+	for _, name := range []string{"first", "second"} {
+		_, span := tracer.Start(context.Background(), name)
+		span.End()
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per span, got %q", content)
+	}
+	var span struct{ Name string }
+	if err := json.Unmarshal([]byte(lines[1]), &span); err != nil || span.Name != "second" {
+		t.Errorf("unexpected span line %q: %v", lines[1], err)
+	}
+
+	for _, exporter := range []string{ExporterNone, ExporterStdout} {
+		cfg.Exporter = exporter
+		shutdown, err := Setup(context.Background(), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := shutdown(context.Background()); err != nil {
+			t.Errorf("%s: %v", exporter, err)
+		}
+	}
+}
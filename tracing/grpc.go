@@ -0,0 +1,429 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const instrumentationName = "github.com/phuthien0308/ordering-base/tracing"
+
+type interceptorOptions struct {
+	provider      trace.TracerProvider
+	propagator    propagation.TextMapPropagator
+	meterProvider metric.MeterProvider
+	baggageLimits *BaggageLimits
+	// unlimitedBaggage disables the default limits of the server interceptors.
+	unlimitedBaggage bool
+}
+
+type InterceptorOption func(*interceptorOptions)
+
+// WithTracerProvider overrides the global provider, mostly for tests.
+func WithTracerProvider(provider trace.TracerProvider) InterceptorOption {
+	return func(o *interceptorOptions) {
+		o.provider = provider
+	}
+}
+
+// WithPropagator overrides the global propagator set by InitGlobalTracer.
+func WithPropagator(propagator propagation.TextMapPropagator) InterceptorOption {
+	return func(o *interceptorOptions) {
+		o.propagator = propagator
+	}
+}
+
+// WithMeterProvider overrides the global meter provider receiving the
+// rpc.server.* and rpc.client.* RED metrics.
+func WithMeterProvider(provider metric.MeterProvider) InterceptorOption {
+	return func(o *interceptorOptions) {
+		o.meterProvider = provider
+	}
+}
+
+// WithBaggageLimits applies LimitBaggage to the baggage received by the server
+// interceptors and sent by the client interceptors. The server interceptors
+// apply DefaultBaggageLimits when it is not set.
+func WithBaggageLimits(limits BaggageLimits) InterceptorOption {
+	return func(o *interceptorOptions) {
+		o.baggageLimits = &limits
+		o.unlimitedBaggage = false
+	}
+}
+
+// WithoutBaggageLimits lets the server interceptors accept any baggage from the
+// callers, only for services reached by trusted callers.
+func WithoutBaggageLimits() InterceptorOption {
+	return func(o *interceptorOptions) {
+		o.baggageLimits = nil
+		o.unlimitedBaggage = true
+	}
+}
+
+func newInterceptorOptions(opts []InterceptorOption) *interceptorOptions {
+	options := &interceptorOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// the globals are read per call so interceptors built before InitGlobalTracer still use them.
+func (o *interceptorOptions) tracer() trace.Tracer {
+	provider := o.provider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(instrumentationName)
+}
+
+// red creates the instruments once per interceptor, the global meter provider
+// forwards them to the provider SetupMetrics installs later. Metrics are
+// skipped if the instruments can not be created.
+func (o *interceptorOptions) red(prefix string) *RED {
+	provider := o.meterProvider
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+	red, err := NewRED(provider.Meter(instrumentationName), prefix)
+	if err != nil {
+		otel.Handle(err)
+		return nil
+	}
+	return red
+}
+
+func (o *interceptorOptions) limitBaggage(ctx context.Context) context.Context {
+	if o.baggageLimits == nil {
+		return ctx
+	}
+	return LimitBaggage(ctx, *o.baggageLimits)
+}
+
+// limitReceivedBaggage is limitBaggage with DefaultBaggageLimits unless disabled.
+func (o *interceptorOptions) limitReceivedBaggage(ctx context.Context) context.Context {
+	if o.baggageLimits == nil && !o.unlimitedBaggage {
+		return LimitBaggage(ctx, DefaultBaggageLimits())
+	}
+	return o.limitBaggage(ctx)
+}
+
+func (o *interceptorOptions) textMapPropagator() propagation.TextMapPropagator {
+	if o.propagator == nil {
+		return otel.GetTextMapPropagator()
+	}
+	return o.propagator
+}
+
+// metadataCarrier adapts gRPC metadata to a propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// methodInfo returns the span name, package.Service/Method as the RPC conventions
+// require, and the rpc.* attributes of fullMethod, shared by spans and metrics.
+func methodInfo(fullMethod string) (string, []attribute.KeyValue) {
+	name := strings.TrimPrefix(fullMethod, "/")
+	attributes := []attribute.KeyValue{semconv.RPCSystemGRPC}
+	if service, method, ok := strings.Cut(name, "/"); ok {
+		attributes = append(attributes, semconv.RPCService(service), semconv.RPCMethod(method))
+	}
+	return name, attributes
+}
+
+// peerAttributes are only set on spans, peers would explode the metrics cardinality.
+func peerAttributes(ctx context.Context) []attribute.KeyValue {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return []attribute.KeyValue{semconv.NetworkPeerAddress(p.Addr.String())}
+	}
+	attributes := []attribute.KeyValue{semconv.NetworkPeerAddress(host)}
+	if portNumber, err := strconv.Atoi(port); err == nil {
+		attributes = append(attributes, semconv.NetworkPeerPort(portNumber))
+	}
+	return attributes
+}
+
+// serverErrorCodes are the codes marking a server span as failed, the other
+// ones are the caller's fault.
+var serverErrorCodes = map[codes.Code]bool{
+	codes.Unknown:          true,
+	codes.DeadlineExceeded: true,
+	codes.Unimplemented:    true,
+	codes.Internal:         true,
+	codes.Unavailable:      true,
+	codes.DataLoss:         true,
+}
+
+// rpcCall is the span and the metrics of one RPC.
+type rpcCall struct {
+	ctx        context.Context
+	span       trace.Span
+	red        *RED
+	start      time.Time
+	attributes []attribute.KeyValue
+	server     bool
+}
+
+func (c *rpcCall) end(err error) {
+	st := status.Convert(err)
+	code := semconv.RPCGRPCStatusCodeKey.Int(int(st.Code()))
+	failed := st.Code() != codes.OK && (!c.server || serverErrorCodes[st.Code()])
+	c.span.SetAttributes(code)
+	if failed {
+		c.span.SetStatus(otelcodes.Error, st.Message())
+	}
+	c.span.End()
+	if c.red != nil {
+		c.red.Record(c.ctx, time.Since(c.start), failed, append(c.attributes, code)...)
+	}
+}
+
+// messageEvent records a message event with the size of proto messages.
+func messageEvent(span trace.Span, messageType attribute.KeyValue, id int64, msg any) {
+	attributes := []attribute.KeyValue{messageType, semconv.RPCMessageIDKey.Int64(id)}
+	if m, ok := msg.(proto.Message); ok {
+		attributes = append(attributes, semconv.RPCMessageUncompressedSizeKey.Int(proto.Size(m)))
+	}
+	span.AddEvent("message", trace.WithAttributes(attributes...))
+}
+
+// UnaryServerInterceptor starts a server span continuing the trace found in the
+// incoming metadata and records the rpc.server.* RED metrics. Put it first so
+// later interceptors log with the trace ids:
+//
+//	grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor(), interceptor.RequestInterceptor(logger, env))
+func UnaryServerInterceptor(opts ...InterceptorOption) grpc.UnaryServerInterceptor {
+	options := newInterceptorOptions(opts)
+	red := options.red("rpc.server")
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, call := startServerCall(ctx, info.FullMethod, options, red)
+		messageEvent(call.span, semconv.RPCMessageTypeReceived, 1, req)
+		resp, err := handler(ctx, req)
+		if err == nil {
+			messageEvent(call.span, semconv.RPCMessageTypeSent, 1, resp)
+		}
+		call.end(err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streams, every message
+// sent or received is recorded as an event.
+func StreamServerInterceptor(opts ...InterceptorOption) grpc.StreamServerInterceptor {
+	options := newInterceptorOptions(opts)
+	red := options.red("rpc.server")
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, call := startServerCall(stream.Context(), info.FullMethod, options, red)
+		err := handler(srv, &serverStream{ServerStream: stream, ctx: ctx, span: call.span})
+		call.end(err)
+		return err
+	}
+}
+
+func startServerCall(ctx context.Context, fullMethod string, options *interceptorOptions, red *RED) (context.Context, *rpcCall) {
+	start := time.Now()
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = options.limitReceivedBaggage(options.textMapPropagator().Extract(ctx, metadataCarrier(md.Copy())))
+	name, attributes := methodInfo(fullMethod)
+	ctx, span := options.tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attributes...), trace.WithAttributes(peerAttributes(ctx)...))
+	return ctx, &rpcCall{ctx: ctx, span: span, red: red, start: start, attributes: attributes, server: true}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	span     trace.Span
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		messageEvent(s.span, semconv.RPCMessageTypeSent, s.sent.Add(1), m)
+	}
+	return err
+}
+
+func (s *serverStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		messageEvent(s.span, semconv.RPCMessageTypeReceived, s.received.Add(1), m)
+	}
+	return err
+}
+
+// UnaryClientInterceptor starts a client span, injects its context in the
+// outgoing metadata and records the rpc.client.* RED metrics.
+func UnaryClientInterceptor(opts ...InterceptorOption) grpc.UnaryClientInterceptor {
+	options := newInterceptorOptions(opts)
+	red := options.red("rpc.client")
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		ctx, call := startClientCall(ctx, method, cc, options, red)
+		messageEvent(call.span, semconv.RPCMessageTypeSent, 1, req)
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		if err == nil {
+			messageEvent(call.span, semconv.RPCMessageTypeReceived, 1, reply)
+		}
+		call.end(err)
+		return err
+	}
+}
+
+// StreamClientInterceptor is UnaryClientInterceptor for streams. The span ends
+// when RecvMsg returns an error, io.EOF meaning the stream completed, when it
+// returns the response of a client-streaming call or when ctx is done.
+func StreamClientInterceptor(opts ...InterceptorOption) grpc.StreamClientInterceptor {
+	options := newInterceptorOptions(opts)
+	red := options.red("rpc.client")
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, call := startClientCall(ctx, method, cc, options, red)
+		stream, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			call.end(err)
+			return nil, err
+		}
+		wrapped := &clientStream{ClientStream: stream, call: call, serverStreams: desc.ServerStreams}
+		// a caller that stops reading before the end of the stream cancels ctx. The
+		// mutex holds back a callback running at once until stop is set.
+		wrapped.mutex.Lock()
+		wrapped.stop = context.AfterFunc(ctx, func() {
+			wrapped.end(status.FromContextError(ctx.Err()).Err())
+		})
+		wrapped.mutex.Unlock()
+		return wrapped, nil
+	}
+}
+
+func startClientCall(ctx context.Context, method string, cc *grpc.ClientConn, options *interceptorOptions, red *RED) (context.Context, *rpcCall) {
+	start := time.Now()
+	ctx = options.limitBaggage(ctx)
+	name, attributes := methodInfo(method)
+	var spanAttributes []attribute.KeyValue
+	if cc != nil {
+		spanAttributes = append(spanAttributes, semconv.ServerAddress(cc.CanonicalTarget()))
+	}
+	ctx, span := options.tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...), trace.WithAttributes(spanAttributes...))
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	options.textMapPropagator().Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), &rpcCall{ctx: ctx, span: span, red: red, start: start, attributes: attributes}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	call          *rpcCall
+	serverStreams bool
+	sent          atomic.Int64
+	received      atomic.Int64
+
+	mutex sync.Mutex
+	stop  func() bool
+	ended bool
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		messageEvent(s.call.span, semconv.RPCMessageTypeSent, s.sent.Add(1), m)
+	} else if !errors.Is(err, io.EOF) {
+		// io.EOF means the real status is returned by RecvMsg.
+		s.end(err)
+	}
+	return err
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		messageEvent(s.call.span, semconv.RPCMessageTypeReceived, s.received.Add(1), m)
+		if !s.serverStreams {
+			// the single response of a client-streaming call, CloseAndRecv reads no io.EOF.
+			s.end(nil)
+		}
+	case errors.Is(err, io.EOF):
+		s.end(nil)
+	default:
+		s.end(err)
+	}
+	return err
+}
+
+func (s *clientStream) Header() (metadata.MD, error) {
+	md, err := s.ClientStream.Header()
+	if err != nil {
+		s.end(err)
+	}
+	return md, err
+}
+
+func (s *clientStream) end(err error) {
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	s.ended = true
+	stop := s.stop
+	s.mutex.Unlock()
+	if stop != nil {
+		stop()
+	}
+	s.call.end(err)
+}
@@ -0,0 +1,208 @@
+package tracing
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func attributeOf(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestInterceptors(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	opts := []InterceptorOption{WithTracerProvider(provider), WithPropagator(propagation.TraceContext{})}
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor(opts...)),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor(opts...)),
+	)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("product", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(UnaryClientInterceptor(opts...)),
+		grpc.WithChainStreamInterceptor(StreamClientInterceptor(opts...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "product"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+	watchCtx, cancel := context.WithCancel(ctx)
+	stream, err := client.Watch(watchCtx, &healthpb.HealthCheckRequest{Service: "product"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	stream.Recv()
+	parent.End()
+	server.GracefulStop()
+
+	var servers, clients []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.SpanKind() {
+		case trace.SpanKindServer:
+			servers = append(servers, span)
+		case trace.SpanKindClient:
+			clients = append(clients, span)
+		}
+	}
+	if len(servers) != 3 || len(clients) != 3 {
+		t.Fatalf("expected 3 server and 3 client spans, got %d and %d", len(servers), len(clients))
+	}
+
+	// the server spans are children of the client spans, in the caller's trace.
+	byID := make(map[trace.SpanID]sdktrace.ReadOnlySpan)
+	for _, span := range clients {
+		byID[span.SpanContext().SpanID()] = span
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("client span %s is not a child of the caller span", span.Name())
+		}
+	}
+	for _, span := range servers {
+		client, ok := byID[span.Parent().SpanID()]
+		if !ok || span.SpanContext().TraceID() != parent.SpanContext().TraceID() || client.Name() != span.Name() {
+			t.Errorf("server span %s did not continue the client trace", span.Name())
+		}
+	}
+
+	check := clients[0]
+	if check.Name() != "grpc.health.v1.Health/Check" ||
+		attributeOf(check, "rpc.system").AsString() != "grpc" ||
+		attributeOf(check, "rpc.service").AsString() != "grpc.health.v1.Health" ||
+		attributeOf(check, "rpc.method").AsString() != "Check" ||
+		attributeOf(check, "rpc.grpc.status_code").AsInt64() != 0 {
+		t.Errorf("unexpected attributes %v on %s", check.Attributes(), check.Name())
+	}
+	if events := check.Events(); len(events) != 2 || events[0].Name != "message" ||
+		events[0].Attributes[0].Value.AsString() != "SENT" || events[0].Attributes[2].Value.AsInt64() != 9 {
+		t.Errorf("unexpected message events %v", events)
+	}
+
+	// NotFound is the caller's fault: only the client span is failed.
+	if clients[1].Status().Code != otelcodes.Error || servers[1].Status().Code != otelcodes.Unset ||
+		attributeOf(servers[1], "rpc.grpc.status_code").AsInt64() != int64(codes.NotFound) {
+		t.Errorf("unexpected statuses %v and %v", clients[1].Status(), servers[1].Status())
+	}
+
+	watch := clients[2]
+	if watch.Name() != "grpc.health.v1.Health/Watch" ||
+		attributeOf(watch, "rpc.grpc.status_code").AsInt64() != int64(codes.Canceled) {
+		t.Errorf("unexpected stream span %s %v", watch.Name(), watch.Attributes())
+	}
+	received := 0
+	for _, event := range servers[2].Events() {
+		if event.Attributes[0].Value.AsString() == "RECEIVED" {
+			received++
+		}
+	}
+	if received != 1 {
+		t.Errorf("expected the request to be recorded on the server stream, got %v", servers[2].Events())
+	}
+}
+
+type fakeClientStream struct {
+	grpc.ClientStream
+}
+
+func (fakeClientStream) SendMsg(any) error { return nil }
+func (fakeClientStream) RecvMsg(any) error { return nil }
+func (fakeClientStream) CloseSend() error  { return nil }
+
+func TestStreamClientInterceptorEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	interceptor := StreamClientInterceptor(WithTracerProvider(provider), WithPropagator(propagation.TraceContext{}))
+	streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return fakeClientStream{}, nil
+	}
+
+	t.Run("client streaming", func(t *testing.T) {
+		recorder.Reset()
+		desc := &grpc.StreamDesc{StreamName: "Upload", ClientStreams: true}
+		stream, _ := interceptor(context.Background(), desc, nil, "/product.ProductService/Upload", streamer)
+		stream.SendMsg(&healthpb.HealthCheckRequest{})
+		stream.SendMsg(&healthpb.HealthCheckRequest{})
+		stream.CloseSend()
+		if err := stream.RecvMsg(&healthpb.HealthCheckResponse{}); err != nil {
+			t.Fatal(err)
+		}
+		ended := recorder.Ended()
+		if len(ended) != 1 || attributeOf(ended[0], "rpc.grpc.status_code").AsInt64() != 0 || len(ended[0].Events()) != 3 {
+			t.Fatalf("expected the span to end with the response, got %v", ended)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		recorder.Reset()
+		ctx, cancel := context.WithCancel(context.Background())
+		desc := &grpc.StreamDesc{StreamName: "Watch", ServerStreams: true}
+		stream, _ := interceptor(ctx, desc, nil, "/grpc.health.v1.Health/Watch", streamer)
+		if err := stream.RecvMsg(&healthpb.HealthCheckResponse{}); err != nil || len(recorder.Ended()) != 0 {
+			t.Fatalf("expected the span to stay open while the server streams, got %v", err)
+		}
+		cancel()
+		for deadline := time.Now().Add(time.Second); len(recorder.Ended()) == 0 && time.Now().Before(deadline); {
+			time.Sleep(time.Millisecond)
+		}
+		ended := recorder.Ended()
+		if len(ended) != 1 || attributeOf(ended[0], "rpc.grpc.status_code").AsInt64() != int64(codes.Canceled) {
+			t.Fatalf("expected the span to end with Canceled, got %v", ended)
+		}
+	})
+
+	t.Run("already cancelled", func(t *testing.T) {
+		recorder.Reset()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		desc := &grpc.StreamDesc{StreamName: "Watch", ServerStreams: true}
+		stream, _ := interceptor(ctx, desc, nil, "/grpc.health.v1.Health/Watch", streamer)
+		stream.RecvMsg(&healthpb.HealthCheckResponse{})
+		for deadline := time.Now().Add(time.Second); len(recorder.Ended()) == 0 && time.Now().Before(deadline); {
+			time.Sleep(time.Millisecond)
+		}
+		if ended := recorder.Ended(); len(ended) != 1 {
+			t.Fatalf("expected the span to end once, got %d spans", len(ended))
+		}
+	})
+}
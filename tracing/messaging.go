@@ -0,0 +1,209 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MessageCarrier carries the trace context in message headers.
+type MessageCarrier = propagation.MapCarrier
+
+// DynamoDBCarrier carries the trace context in string attributes of a DynamoDB
+// item, the producer writes them with the item and the consumer reads them from
+// the NewImage of the stream record. V is the attribute value type of the SDK in
+// use, String reads a string attribute and Value builds one.
+//
+// The attributes are stored with the item: with the default propagator, the
+// baggage, user_id included, is persisted in a baggage attribute. Pass
+// WithMessagingPropagator(propagation.TraceContext{}) to only store traceparent
+// and tracestate:
+//
+//	carrier := tracing.DynamoDBCarrier[events.DynamoDBAttributeValue]{
+//		Attributes: record.Change.NewImage,
+//		String: func(v events.DynamoDBAttributeValue) (string, bool) {
+//			return v.String(), v.DataType() == events.DataTypeString
+//		},
+//	}
+type DynamoDBCarrier[V any] struct {
+	Attributes map[string]V
+	String     func(V) (string, bool)
+	// Value is only needed to inject.
+	Value func(string) V
+}
+
+// NewDynamoDBJSONCarrier reads and writes attributes in the DynamoDB JSON
+// encoding of stream events, {"traceparent": {"S": "00-..."}}.
+func NewDynamoDBJSONCarrier(attributes map[string]map[string]any) DynamoDBCarrier[map[string]any] {
+	return DynamoDBCarrier[map[string]any]{
+		Attributes: attributes,
+		String: func(v map[string]any) (string, bool) {
+			s, ok := v["S"].(string)
+			return s, ok
+		},
+		Value: func(s string) map[string]any { return map[string]any{"S": s} },
+	}
+}
+
+func (c DynamoDBCarrier[V]) Get(key string) string {
+	v, ok := c.Attributes[key]
+	if !ok || c.String == nil {
+		return ""
+	}
+	s, _ := c.String(v)
+	return s
+}
+
+func (c DynamoDBCarrier[V]) Set(key string, value string) {
+	if c.Value != nil && c.Attributes != nil {
+		c.Attributes[key] = c.Value(value)
+	}
+}
+
+func (c DynamoDBCarrier[V]) Keys() []string {
+	if c.String == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(c.Attributes))
+	for key, v := range c.Attributes {
+		if _, ok := c.String(v); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+type messagingOptions struct {
+	provider      trace.TracerProvider
+	propagator    propagation.TextMapPropagator
+	attributes    []attribute.KeyValue
+	baggageLimits *BaggageLimits
+}
+
+type MessagingOption func(*messagingOptions)
+
+// WithMessagingTracerProvider overrides the global provider, mostly for tests.
+func WithMessagingTracerProvider(provider trace.TracerProvider) MessagingOption {
+	return func(o *messagingOptions) {
+		o.provider = provider
+	}
+}
+
+// WithMessagingPropagator overrides the global propagator set by InitGlobalTracer.
+func WithMessagingPropagator(propagator propagation.TextMapPropagator) MessagingOption {
+	return func(o *messagingOptions) {
+		o.propagator = propagator
+	}
+}
+
+// WithMessageBaggageLimits replaces DefaultBaggageLimits, applied to the baggage
+// read from messages. An empty BaggageLimits keeps every member.
+func WithMessageBaggageLimits(limits BaggageLimits) MessagingOption {
+	return func(o *messagingOptions) {
+		o.baggageLimits = &limits
+	}
+}
+
+// WithMessagingSystem sets messaging.system, e.g. kafka or aws_sqs.
+func WithMessagingSystem(system string) MessagingOption {
+	return WithMessageAttributes(semconv.MessagingSystemKey.String(system))
+}
+
+// WithMessageAttributes adds attributes to the producer and consumer spans.
+func WithMessageAttributes(attributes ...attribute.KeyValue) MessagingOption {
+	return func(o *messagingOptions) {
+		o.attributes = append(o.attributes, attributes...)
+	}
+}
+
+func newMessagingOptions(opts []MessagingOption) *messagingOptions {
+	options := &messagingOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.provider == nil {
+		options.provider = otel.GetTracerProvider()
+	}
+	if options.propagator == nil {
+		options.propagator = otel.GetTextMapPropagator()
+	}
+	if options.baggageLimits == nil {
+		limits := DefaultBaggageLimits()
+		options.baggageLimits = &limits
+	}
+	return options
+}
+
+// extract reads the context of a message, its baggage bounded like the baggage
+// received by the gRPC servers.
+func (o *messagingOptions) extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return LimitBaggage(o.propagator.Extract(ctx, carrier), *o.baggageLimits)
+}
+
+func (o *messagingOptions) start(ctx context.Context, name string, kind trace.SpanKind, attributes []attribute.KeyValue, links ...trace.Link) (context.Context, trace.Span) {
+	return o.provider.Tracer(instrumentationName).Start(ctx, name, trace.WithSpanKind(kind),
+		trace.WithAttributes(attributes...), trace.WithAttributes(o.attributes...), trace.WithLinks(links...))
+}
+
+// InjectMessage writes the trace context and baggage of ctx to carrier, for
+// producers that already have a span, e.g. a DynamoDB PutItem.
+func InjectMessage(ctx context.Context, carrier propagation.TextMapCarrier, opts ...MessagingOption) {
+	newMessagingOptions(opts).propagator.Inject(ctx, carrier)
+}
+
+// ExtractMessage returns a copy of ctx with the trace context and the limited
+// baggage read from carrier.
+func ExtractMessage(ctx context.Context, carrier propagation.TextMapCarrier, opts ...MessagingOption) context.Context {
+	return newMessagingOptions(opts).extract(ctx, carrier)
+}
+
+// StartProducer starts a producer span for a message sent to destination and
+// writes its context to carrier, the span ends once the message is sent:
+//
+//	headers := tracing.MessageCarrier{}
+//	ctx, span := tracing.StartProducer(ctx, "product-events", headers, tracing.WithMessagingSystem("kafka"))
+//	defer span.End()
+func StartProducer(ctx context.Context, destination string, carrier propagation.TextMapCarrier, opts ...MessagingOption) (context.Context, trace.Span) {
+	options := newMessagingOptions(opts)
+	ctx, span := options.start(ctx, destination+" publish", trace.SpanKindProducer,
+		[]attribute.KeyValue{semconv.MessagingDestinationName(destination), semconv.MessagingOperationTypePublish})
+	options.propagator.Inject(ctx, carrier)
+	return ctx, span
+}
+
+// StartConsumer starts a consumer span processing a message of destination, as a
+// child of the producer span found in carrier and linked to it. The baggage of
+// the message, within DefaultBaggageLimits, is in the returned context.
+func StartConsumer(ctx context.Context, destination string, carrier propagation.TextMapCarrier, opts ...MessagingOption) (context.Context, trace.Span) {
+	options := newMessagingOptions(opts)
+	ctx = options.extract(ctx, carrier)
+	var links []trace.Link
+	if producer := trace.SpanContextFromContext(ctx); producer.IsValid() {
+		links = append(links, trace.Link{SpanContext: producer})
+	}
+	return options.start(ctx, destination+" process", trace.SpanKindConsumer,
+		[]attribute.KeyValue{semconv.MessagingDestinationName(destination), semconv.MessagingOperationTypeDeliver}, links...)
+}
+
+// StartBatchConsumer starts one consumer span processing a batch of messages of
+// destination, in the trace of ctx and linked to the producer span of every
+// message. The baggage of the messages is not copied and the baggage of ctx is
+// limited like the one of a single message.
+func StartBatchConsumer(ctx context.Context, destination string, carriers []propagation.TextMapCarrier, opts ...MessagingOption) (context.Context, trace.Span) {
+	options := newMessagingOptions(opts)
+	ctx = LimitBaggage(ctx, *options.baggageLimits)
+	links := make([]trace.Link, 0, len(carriers))
+	for _, carrier := range carriers {
+		producer := trace.SpanContextFromContext(options.extract(context.Background(), carrier))
+		if producer.IsValid() {
+			links = append(links, trace.Link{SpanContext: producer})
+		}
+	}
+	return options.start(ctx, destination+" process", trace.SpanKindConsumer,
+		[]attribute.KeyValue{semconv.MessagingDestinationName(destination), semconv.MessagingOperationTypeDeliver,
+			semconv.MessagingBatchMessageCount(len(carriers))}, links...)
+}
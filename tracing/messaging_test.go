@@ -0,0 +1,112 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newMessagingRecorder() (*tracetest.SpanRecorder, []MessagingOption) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	return recorder, []MessagingOption{WithMessagingTracerProvider(provider), WithMessagingPropagator(propagator), WithMessagingSystem("kafka")}
+}
+
+func TestProducerConsumer(t *testing.T) {
+	recorder, opts := newMessagingRecorder()
+	ctx, _ := WithTenantID(context.Background(), "acme")
+	ctx, _ = SetBaggage(ctx, "role", "admin")
+
+	headers := MessageCarrier{}
+	_, producer := StartProducer(ctx, "product-events", headers, opts...)
+	producer.End()
+	if headers["traceparent"] == "" || headers["baggage"] == "" {
+		t.Fatalf("expected the trace context in the headers, got %v", headers)
+	}
+
+	ctx, consumer := StartConsumer(context.Background(), "product-events", headers, opts...)
+	consumer.End()
+	if TenantID(ctx) != "acme" || baggage.FromContext(ctx).Len() != 1 {
+		t.Errorf("expected the limited baggage of the message, got %s", baggage.FromContext(ctx))
+	}
+	ctx = ExtractMessage(context.Background(), headers, append(opts, WithMessageBaggageLimits(BaggageLimits{}))...)
+	if baggage.FromContext(ctx).Member("role").Value() != "admin" {
+		t.Errorf("expected the limits to be replaced, got %s", baggage.FromContext(ctx))
+	}
+
+	ended := recorder.Ended()
+	if ended[0].Name() != "product-events publish" || ended[0].SpanKind() != trace.SpanKindProducer {
+		t.Errorf("unexpected producer span %s (%s)", ended[0].Name(), ended[0].SpanKind())
+	}
+	span := ended[1]
+	if span.Name() != "product-events process" || span.SpanKind() != trace.SpanKindConsumer ||
+		span.Parent().SpanID() != producer.SpanContext().SpanID() {
+		t.Errorf("expected a consumer child of the producer, got %s (%s) parent %s", span.Name(), span.SpanKind(), span.Parent().SpanID())
+	}
+	if len(span.Links()) != 1 || span.Links()[0].SpanContext.SpanID() != producer.SpanContext().SpanID() {
+		t.Errorf("expected a link to the producer, got %v", span.Links())
+	}
+}
+
+func TestBatchConsumer(t *testing.T) {
+	recorder, opts := newMessagingRecorder()
+	var carriers []propagation.TextMapCarrier
+	var producers []trace.SpanContext
+	for range 3 {
+		item := map[string]map[string]any{"sku": {"S": "SKU-1"}, "price": {"N": "10"}}
+		carrier := NewDynamoDBJSONCarrier(item)
+		_, producer := StartProducer(context.Background(), "products", carrier, opts...)
+		producer.End()
+		if _, ok := item["traceparent"]["S"].(string); !ok {
+			t.Fatalf("expected a string attribute traceparent, got %v", item)
+		}
+		carriers = append(carriers, carrier)
+		producers = append(producers, producer.SpanContext())
+	}
+	// a record written without trace context.
+	carriers = append(carriers, NewDynamoDBJSONCarrier(map[string]map[string]any{"sku": {"S": "SKU-2"}}))
+
+	_, batch := StartBatchConsumer(context.Background(), "products", carriers, opts...)
+	batch.End()
+
+	span := recorder.Ended()[3]
+	if span.Parent().IsValid() || len(span.Links()) != 3 {
+		t.Fatalf("expected a root span with 3 links, got parent %v and %d links", span.Parent(), len(span.Links()))
+	}
+	for i, link := range span.Links() {
+		if link.SpanContext.SpanID() != producers[i].SpanID() {
+			t.Errorf("link %d: expected %s, got %s", i, producers[i].SpanID(), link.SpanContext.SpanID())
+		}
+	}
+}
+
+func TestDynamoDBCarrierTraceContextOnly(t *testing.T) {
+	_, opts := newMessagingRecorder()
+	ctx, _ := WithUserID(context.Background(), "u-1")
+	item := map[string]map[string]any{"sku": {"S": "SKU-1"}}
+	_, producer := StartProducer(ctx, "products", NewDynamoDBJSONCarrier(item), append(opts, WithMessagingPropagator(propagation.TraceContext{}))...)
+	producer.End()
+	if _, ok := item["baggage"]; ok || item["traceparent"] == nil {
+		t.Errorf("expected only the trace context on the item, got %v", item)
+	}
+}
+
+func TestDynamoDBCarrier(t *testing.T) {
+	carrier := NewDynamoDBJSONCarrier(map[string]map[string]any{"traceparent": {"S": "00-x"}, "price": {"N": "10"}})
+	if carrier.Get("traceparent") != "00-x" || carrier.Get("price") != "" || carrier.Get("missing") != "" {
+		t.Errorf("unexpected values %v", carrier.Attributes)
+	}
+	if keys := carrier.Keys(); len(keys) != 1 || keys[0] != "traceparent" {
+		t.Errorf("expected only the string attributes, got %v", keys)
+	}
+	carrier.String = nil
+	if carrier.Get("traceparent") != "" || len(carrier.Keys()) != 0 {
+		t.Error("expected a carrier without String to read nothing")
+	}
+}
@@ -0,0 +1,238 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+const (
+	MetricsExporterPrometheus = "prometheus"
+	MetricsExporterOTLP       = "otlp"
+	MetricsExporterStdout     = "stdout"
+	MetricsExporterNone       = "none"
+)
+
+// MetricsConfig describes the meter provider built by SetupMetrics.
+type MetricsConfig struct {
+	ServiceName string
+	// Exporter is one of the MetricsExporter* constants.
+	Exporter string
+	// Protocol, Endpoint, Headers and Insecure configure the OTLP exporter as in Config.
+	Protocol string
+	Endpoint string
+	Headers  map[string]string
+	Insecure bool
+	// Interval between two pushes of the OTLP and stdout exporters, zero keeps the SDK default.
+	Interval time.Duration
+}
+
+func DefaultMetricsConfig(serviceName string) MetricsConfig {
+	return MetricsConfig{
+		ServiceName: serviceName,
+		Exporter:    MetricsExporterPrometheus,
+		Protocol:    ProtocolHTTP,
+	}
+}
+
+// MetricsConfigFromEnv reads OTEL_SERVICE_NAME, OTEL_METRICS_EXPORTER (console is
+// an alias of stdout), OTEL_EXPORTER_OTLP_[METRICS_]{PROTOCOL,ENDPOINT,HEADERS,
+// INSECURE} and OTEL_METRIC_EXPORT_INTERVAL on top of DefaultMetricsConfig.
+func MetricsConfigFromEnv(serviceName string) (MetricsConfig, error) {
+	cfg := DefaultMetricsConfig(serviceName)
+	if v := os.Getenv("OTEL_SERVICE_NAME"); v != "" {
+		cfg.ServiceName = v
+	}
+	if v := os.Getenv("OTEL_METRICS_EXPORTER"); v != "" {
+		cfg.Exporter = strings.TrimSpace(strings.Split(v, ",")[0])
+		if cfg.Exporter == "console" {
+			cfg.Exporter = MetricsExporterStdout
+		}
+	}
+	lookup := func(name string) string {
+		if v := os.Getenv("OTEL_EXPORTER_OTLP_METRICS_" + name); v != "" {
+			return v
+		}
+		return os.Getenv("OTEL_EXPORTER_OTLP_" + name)
+	}
+	if v := lookup("PROTOCOL"); v != "" {
+		cfg.Protocol = v
+	}
+	cfg.Endpoint = otlpEndpoint("metrics", cfg.Protocol)
+	if v := lookup("HEADERS"); v != "" {
+		headers, err := parseOTLPHeaders(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Headers = headers
+	}
+	if v := lookup("INSECURE"); v != "" {
+		insecure, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid OTLP insecure %q: %w", v, err)
+		}
+		cfg.Insecure = insecure
+	}
+	if v := os.Getenv("OTEL_METRIC_EXPORT_INTERVAL"); v != "" {
+		ms, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid OTEL_METRIC_EXPORT_INTERVAL %q, expected milliseconds: %w", v, err)
+		}
+		cfg.Interval = time.Duration(ms) * time.Millisecond
+	}
+	return cfg, nil
+}
+
+func (c MetricsConfig) Validate() error {
+	if c.ServiceName == "" {
+		return fmt.Errorf("service name is required")
+	}
+	switch c.Exporter {
+	case MetricsExporterOTLP:
+		if c.Protocol != ProtocolGRPC && c.Protocol != ProtocolHTTP {
+			return fmt.Errorf("invalid OTLP protocol %q, expected grpc or http/protobuf", c.Protocol)
+		}
+	case MetricsExporterPrometheus, MetricsExporterStdout, MetricsExporterNone:
+	default:
+		return fmt.Errorf("invalid metrics exporter %q", c.Exporter)
+	}
+	if c.Interval < 0 {
+		return fmt.Errorf("metrics interval must not be negative")
+	}
+	return nil
+}
+
+// Metrics is the meter provider installed by SetupMetrics.
+type Metrics struct {
+	Provider *sdkmetric.MeterProvider
+	// Handler serves the Prometheus scrape endpoint, nil for the push exporters.
+	Handler http.Handler
+}
+
+// SetupMetrics builds the meter provider cfg describes, with the same resource
+// attributes as the tracer provider of Setup, and installs it as the global one:
+//
+//	metrics, shutdown, err := tracing.SetupMetrics(ctx, tracing.DefaultMetricsConfig("productservice"))
+//	defer shutdown(context.Background())
+//	http.Handle("/metrics", metrics.Handler)
+func SetupMetrics(ctx context.Context, cfg MetricsConfig) (*Metrics, func(context.Context) error, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	res, err := newResource(ctx, cfg.ServiceName)
+	if err != nil {
+		return nil, nil, err
+	}
+	metrics := &Metrics{}
+	var reader sdkmetric.Reader
+	switch cfg.Exporter {
+	case MetricsExporterPrometheus:
+		registry := prometheus.NewRegistry()
+		exporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create prometheus exporter: %w", err)
+		}
+		reader = exporter
+		metrics.Handler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	case MetricsExporterOTLP, MetricsExporterStdout:
+		exporter, err := newMetricExporter(ctx, cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create metrics exporter: %w", err)
+		}
+		var opts []sdkmetric.PeriodicReaderOption
+		if cfg.Interval > 0 {
+			opts = append(opts, sdkmetric.WithInterval(cfg.Interval))
+		}
+		reader = sdkmetric.NewPeriodicReader(exporter, opts...)
+	}
+	providerOptions := []sdkmetric.Option{sdkmetric.WithResource(res)}
+	if reader != nil {
+		providerOptions = append(providerOptions, sdkmetric.WithReader(reader))
+	}
+	metrics.Provider = sdkmetric.NewMeterProvider(providerOptions...)
+	otel.SetMeterProvider(metrics.Provider)
+	return metrics, metrics.Provider.Shutdown, nil
+}
+
+func newMetricExporter(ctx context.Context, cfg MetricsConfig) (sdkmetric.Exporter, error) {
+	if cfg.Exporter == MetricsExporterStdout {
+		return stdoutmetric.New(stdoutmetric.WithPrettyPrint())
+	}
+	if cfg.Protocol == ProtocolGRPC {
+		opts := []otlpmetricgrpc.Option{}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlpmetricgrpc.WithEndpointURL(cfg.Endpoint))
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlpmetricgrpc.WithHeaders(cfg.Headers))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	}
+	opts := []otlpmetrichttp.Option{}
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlpmetrichttp.WithEndpointURL(cfg.Endpoint))
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlpmetrichttp.WithHeaders(cfg.Headers))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	}
+	return otlpmetrichttp.New(ctx, opts...)
+}
+
+// RED holds the rate, errors and duration instruments of an operation, named
+// <prefix>.requests, <prefix>.errors and <prefix>.duration (in milliseconds).
+type RED struct {
+	requests metric.Int64Counter
+	errors   metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+// NewRED creates the instruments on meter, e.g. with the prefix "rpc.server".
+func NewRED(meter metric.Meter, prefix string) (*RED, error) {
+	requests, err := meter.Int64Counter(prefix+".requests", metric.WithUnit("{request}"),
+		metric.WithDescription("Number of requests handled."))
+	if err != nil {
+		return nil, err
+	}
+	errors, err := meter.Int64Counter(prefix+".errors", metric.WithUnit("{request}"),
+		metric.WithDescription("Number of requests that failed."))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram(prefix+".duration", metric.WithUnit("ms"),
+		metric.WithDescription("Duration of the requests."))
+	if err != nil {
+		return nil, err
+	}
+	return &RED{requests: requests, errors: errors, duration: duration}, nil
+}
+
+// Record counts one request, as failed when failed is true, and its duration.
+func (r *RED) Record(ctx context.Context, duration time.Duration, failed bool, attributes ...attribute.KeyValue) {
+	set := metric.WithAttributeSet(attribute.NewSet(attributes...))
+	r.requests.Add(ctx, 1, set)
+	if failed {
+		r.errors.Add(ctx, 1, set)
+	}
+	r.duration.Record(ctx, float64(duration)/float64(time.Millisecond), set)
+}
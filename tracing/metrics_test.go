@@ -0,0 +1,117 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetricsConfigFromEnv(t *testing.T) {
+	t.Setenv("OTEL_METRICS_EXPORTER", "otlp")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "grpc")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4317")
+	t.Setenv("OTEL_METRIC_EXPORT_INTERVAL", "15000")
+
+	cfg, err := MetricsConfigFromEnv("productservice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Exporter != MetricsExporterOTLP || cfg.Protocol != ProtocolGRPC || cfg.Endpoint != "http://collector:4317" ||
+		cfg.Interval != 15*time.Second {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=a%3Db")
+	if cfg, _ := MetricsConfigFromEnv("productservice"); cfg.Endpoint != "http://collector:4318/v1/metrics" || cfg.Headers["api-key"] != "a=b" {
+		t.Errorf("unexpected endpoint %s or headers %v", cfg.Endpoint, cfg.Headers)
+	}
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "http://metrics:4318/custom")
+	if cfg, _ := MetricsConfigFromEnv("productservice"); cfg.Endpoint != "http://metrics:4318/custom" {
+		t.Errorf("expected the metrics endpoint as is, got %s", cfg.Endpoint)
+	}
+
+	cfg.Exporter = "statsd"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected an invalid exporter to be rejected")
+	}
+}
+
+func TestSetupMetricsPrometheus(t *testing.T) {
+	previous := otel.GetMeterProvider()
+	defer otel.SetMeterProvider(previous)
+
+	metrics, shutdown, err := SetupMetrics(context.Background(), DefaultMetricsConfig("productservice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(context.Background())
+
+	red, err := NewRED(otel.Meter("test"), "rpc.server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	red.Record(context.Background(), 12*time.Millisecond, true, attribute.String("rpc.method", "CreateProduct"))
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+	for _, expected := range []string{
+		`rpc_server_requests_total{otel_scope_name="test"`,
+		`rpc_server_errors_total{otel_scope_name="test"`,
+		`rpc_server_duration_milliseconds_bucket{`,
+		`service_name="productservice"`,
+	} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("expected %s in the scrape, got:\n%s", expected, body)
+		}
+	}
+}
+
+func TestInterceptorMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	interceptor := UnaryServerInterceptor(WithMeterProvider(provider))
+	info := &grpc.UnaryServerInfo{FullMethod: "/product.ProductService/CreateProduct"}
+
+	for _, err := range []error{nil, status.Error(codes.InvalidArgument, "bad sku"), status.Error(codes.Internal, "boom")} {
+		interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) { return nil, err })
+	}
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatal(err)
+	}
+	totals := make(map[string]int64)
+	for _, m := range data.ScopeMetrics[0].Metrics {
+		switch values := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			for _, point := range values.DataPoints {
+				totals[m.Name] += point.Value
+				if method, _ := point.Attributes.Value("rpc.method"); method.AsString() != "CreateProduct" {
+					t.Errorf("unexpected attributes %v", point.Attributes.ToSlice())
+				}
+			}
+		case metricdata.Histogram[float64]:
+			for _, point := range values.DataPoints {
+				totals[m.Name] += int64(point.Count)
+			}
+		}
+	}
+	// InvalidArgument is the caller's fault and does not count as a server error.
+	if totals["rpc.server.requests"] != 3 || totals["rpc.server.errors"] != 1 || totals["rpc.server.duration"] != 3 {
+		t.Errorf("unexpected totals %v", totals)
+	}
+}
@@ -0,0 +1,143 @@
+package tracing
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// HealthCheckPattern matches the spans of the standard gRPC health service.
+const HealthCheckPattern = "grpc.health.v1.Health/*"
+
+// SamplingRule decides the sampling of the root spans it matches, the children
+// follow their parent so a trace is never broken.
+type SamplingRule struct {
+	// SpanName is a path.Match pattern such as "product.ProductService/*", empty matches every span.
+	SpanName string
+	// Attributes must all be among the attributes the span is started with.
+	Attributes []attribute.KeyValue
+	// Ratio of the matching traces to sample, 1 samples all of them and 0 none.
+	Ratio float64
+}
+
+// AlwaysSample samples every span whose name matches pattern.
+func AlwaysSample(pattern string) SamplingRule {
+	return SamplingRule{SpanName: pattern, Ratio: 1}
+}
+
+// NeverSample drops every span whose name matches pattern, e.g. HealthCheckPattern.
+func NeverSample(pattern string) SamplingRule {
+	return SamplingRule{SpanName: pattern, Ratio: 0}
+}
+
+// SampleAttribute samples ratio of the spans started with the attribute kv.
+func SampleAttribute(kv attribute.KeyValue, ratio float64) SamplingRule {
+	return SamplingRule{Attributes: []attribute.KeyValue{kv}, Ratio: ratio}
+}
+
+func (r SamplingRule) validate() error {
+	if _, err := path.Match(r.SpanName, ""); err != nil {
+		return fmt.Errorf("invalid span name pattern %q: %w", r.SpanName, err)
+	}
+	return validateRatio(r.Ratio)
+}
+
+func (r SamplingRule) matches(p sdktrace.SamplingParameters) bool {
+	if r.SpanName != "" {
+		if ok, _ := path.Match(r.SpanName, p.Name); !ok {
+			return false
+		}
+	}
+	for _, kv := range r.Attributes {
+		if !slices.Contains(p.Attributes, kv) {
+			return false
+		}
+	}
+	return true
+}
+
+func validateRatio(ratio float64) error {
+	if ratio < 0 || ratio > 1 {
+		return fmt.Errorf("sampling ratio %v must be between 0 and 1", ratio)
+	}
+	return nil
+}
+
+type samplerConfig struct {
+	ratio    float64
+	rules    []SamplingRule
+	samplers []sdktrace.Sampler
+	fallback sdktrace.Sampler
+}
+
+// Sampler follows the parent decision and, for the new traces, applies the first
+// matching rule or samples ratio of them. Its settings can be changed while the
+// provider is running.
+type Sampler struct {
+	config atomic.Pointer[samplerConfig]
+}
+
+// NewSampler returns a Sampler keeping ratio of the root spans.
+func NewSampler(ratio float64, rules ...SamplingRule) (*Sampler, error) {
+	sampler := &Sampler{}
+	if err := sampler.set(ratio, rules); err != nil {
+		return nil, err
+	}
+	return sampler, nil
+}
+
+func (s *Sampler) set(ratio float64, rules []SamplingRule) error {
+	if err := validateRatio(ratio); err != nil {
+		return err
+	}
+	config := &samplerConfig{
+		ratio:    ratio,
+		rules:    slices.Clone(rules),
+		fallback: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio)),
+	}
+	for _, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+		config.samplers = append(config.samplers, sdktrace.ParentBased(sdktrace.TraceIDRatioBased(rule.Ratio)))
+	}
+	s.config.Store(config)
+	return nil
+}
+
+// SetRatio changes the ratio of the root spans sampled, the rules are kept.
+func (s *Sampler) SetRatio(ratio float64) error {
+	return s.set(ratio, s.config.Load().rules)
+}
+
+// SetRules replaces the rules, the ratio is kept.
+func (s *Sampler) SetRules(rules ...SamplingRule) error {
+	return s.set(s.config.Load().ratio, rules)
+}
+
+func (s *Sampler) Ratio() float64 {
+	return s.config.Load().ratio
+}
+
+func (s *Sampler) Rules() []SamplingRule {
+	return slices.Clone(s.config.Load().rules)
+}
+
+func (s *Sampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	config := s.config.Load()
+	for i, rule := range config.rules {
+		if rule.matches(p) {
+			return config.samplers[i].ShouldSample(p)
+		}
+	}
+	return config.fallback.ShouldSample(p)
+}
+
+func (s *Sampler) Description() string {
+	config := s.config.Load()
+	return fmt.Sprintf("RuleSampler{ratio:%g,rules:%d}", config.ratio, len(config.rules))
+}
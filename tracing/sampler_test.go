@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSampler(t *testing.T) {
+	sampler, err := NewSampler(0,
+		NeverSample(HealthCheckPattern),
+		AlwaysSample("product.ProductService/*"),
+		SampleAttribute(attribute.String("tenant", "premium"), 1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler))
+	tracer := provider.Tracer("test")
+	sampled := func(ctx context.Context, name string, attributes ...attribute.KeyValue) bool {
+		_, span := tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+		defer span.End()
+		return span.SpanContext().IsSampled()
+	}
+
+	tcs := []struct {
+		name       string
+		span       string
+		attributes []attribute.KeyValue
+		expected   bool
+	}{
+		{name: "rule always", span: "product.ProductService/CreateProduct", expected: true},
+		{name: "rule never", span: "grpc.health.v1.Health/Check", expected: false},
+		{name: "rule attribute", span: "account.AccountService/Get", attributes: []attribute.KeyValue{attribute.String("tenant", "premium")}, expected: true},
+		{name: "ratio", span: "account.AccountService/Get", attributes: []attribute.KeyValue{attribute.String("tenant", "free")}, expected: false},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := sampled(context.Background(), tc.span, tc.attributes...); got != tc.expected {
+				t.Errorf("expected sampled=%v, got %v", tc.expected, got)
+			}
+		})
+	}
+
+	// children follow their parent, even when a rule matches.
+	ctx, parent := tracer.Start(context.Background(), "product.ProductService/GetProduct")
+	if !sampled(ctx, "dao.GetProduct") || !sampled(ctx, "grpc.health.v1.Health/Check") {
+		t.Error("expected the children to follow the sampled parent")
+	}
+	parent.End()
+	remote := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1}, TraceFlags: trace.FlagsSampled, Remote: true})
+	if !sampled(trace.ContextWithRemoteSpanContext(context.Background(), remote), "grpc.health.v1.Health/Check") {
+		t.Error("expected the child to follow the sampled remote parent")
+	}
+	remote = remote.WithTraceFlags(0)
+	if sampled(trace.ContextWithRemoteSpanContext(context.Background(), remote), "product.ProductService/CreateProduct") {
+		t.Error("expected the child to follow the unsampled remote parent")
+	}
+
+	if err := sampler.SetRatio(1); err != nil {
+		t.Fatal(err)
+	}
+	if !sampled(context.Background(), "account.AccountService/Get") || sampled(context.Background(), "grpc.health.v1.Health/Watch") {
+		t.Error("expected the new ratio to apply and the rules to be kept")
+	}
+	if err := sampler.SetRules(); err != nil || !sampled(context.Background(), "grpc.health.v1.Health/Watch") {
+		t.Errorf("expected the rules to be removed, got %v", err)
+	}
+
+	if err := sampler.SetRatio(1.5); err == nil || sampler.Ratio() != 1 {
+		t.Error("expected an invalid ratio to be rejected and the previous one kept")
+	}
+	if _, err := NewSampler(0.5, NeverSample("[")); err == nil {
+		t.Error("expected an invalid pattern to be rejected")
+	}
+}
@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type spanOptions struct {
+	tracer     trace.Tracer
+	kind       trace.SpanKind
+	attributes []attribute.KeyValue
+}
+
+type SpanOption func(*spanOptions)
+
+// WithSpanTracer overrides the tracer of the global provider.
+func WithSpanTracer(tracer trace.Tracer) SpanOption {
+	return func(o *spanOptions) {
+		o.tracer = tracer
+	}
+}
+
+// WithSpanKind replaces the default internal kind.
+func WithSpanKind(kind trace.SpanKind) SpanOption {
+	return func(o *spanOptions) {
+		o.kind = kind
+	}
+}
+
+// WithSpanAttributes adds attributes to the span.
+func WithSpanAttributes(attributes ...attribute.KeyValue) SpanOption {
+	return func(o *spanOptions) {
+		o.attributes = append(o.attributes, attributes...)
+	}
+}
+
+// Do runs fn in a span named name. A returned error is recorded and sets the
+// span status to error, a panic is recorded with its stack trace and panics again
+// once the span has ended:
+//
+//	product, err := tracing.Do(ctx, "dynamodb.GetItem", func(ctx context.Context) (*Product, error) {
+//		return dao.Get(ctx, sku)
+//	}, tracing.WithSpanKind(trace.SpanKindClient), tracing.WithSpanAttributes(attribute.String("sku", sku)))
+func Do[T any](ctx context.Context, name string, fn func(context.Context) (T, error), opts ...SpanOption) (T, error) {
+	options := spanOptions{kind: trace.SpanKindInternal}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.tracer == nil {
+		options.tracer = otel.Tracer(instrumentationName)
+	}
+	ctx, span := options.tracer.Start(ctx, name, trace.WithSpanKind(options.kind), trace.WithAttributes(options.attributes...))
+	var (
+		result T
+		err    error
+	)
+	// the span also ends when fn calls runtime.Goexit, e.g. through t.FailNow,
+	// where recover returns nil.
+	defer func() {
+		r := recover()
+		switch {
+		case r != nil:
+			panicErr := fmt.Errorf("panic: %v", r)
+			span.RecordError(panicErr, trace.WithStackTrace(true))
+			span.SetStatus(codes.Error, panicErr.Error())
+		case err != nil:
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+		if r != nil {
+			panic(r)
+		}
+	}()
+	result, err = fn(ctx)
+	return result, err
+}
+
+// Run is Do for functions returning only an error.
+func Run(ctx context.Context, name string, fn func(context.Context) error, opts ...SpanOption) error {
+	_, err := Do(ctx, name, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	}, opts...)
+	return err
+}
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestDo(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	sku := attribute.String("sku", "SKU-1")
+
+	tests := []struct {
+		name   string
+		fn     func(context.Context) (int, error)
+		status codes.Code
+		event  bool
+	}{
+		{"success", func(context.Context) (int, error) { return 1, nil }, codes.Unset, false},
+		{"error", func(context.Context) (int, error) { return 0, errors.New("not found") }, codes.Error, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Reset()
+			var inner trace.SpanContext
+			_, err := Do(context.Background(), "dynamodb.GetItem", func(ctx context.Context) (int, error) {
+				inner = trace.SpanContextFromContext(ctx)
+				return tt.fn(ctx)
+			}, WithSpanTracer(tracer), WithSpanKind(trace.SpanKindClient), WithSpanAttributes(sku))
+			if _, expected := tt.fn(context.Background()); (err != nil) != (expected != nil) {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			span := recorder.Ended()[0]
+			if span.SpanContext().SpanID() != inner.SpanID() {
+				t.Error("expected fn to run in the span context")
+			}
+			if span.SpanKind() != trace.SpanKindClient || len(span.Attributes()) != 1 || span.Attributes()[0] != sku {
+				t.Errorf("unexpected kind %s or attributes %v", span.SpanKind(), span.Attributes())
+			}
+			if span.Status().Code != tt.status || (len(span.Events()) == 1) != tt.event {
+				t.Errorf("unexpected status %v or events %v", span.Status(), span.Events())
+			}
+		})
+	}
+}
+
+func TestRunPanic(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("expected the panic to propagate, got %v", r)
+		}
+		span := recorder.Ended()[0]
+		if span.Status().Code != codes.Error || span.Status().Description != "panic: boom" {
+			t.Errorf("unexpected status %v", span.Status())
+		}
+		event := span.Events()[0]
+		if event.Name != "exception" || !slices.ContainsFunc(event.Attributes, func(kv attribute.KeyValue) bool { return kv.Key == "exception.stacktrace" }) {
+			t.Errorf("expected an exception event with the stack trace, got %v", event)
+		}
+	}()
+	Run(context.Background(), "index", func(context.Context) error { panic("boom") }, WithSpanTracer(tracer))
+}
+
+func TestRunGoexit(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Run(context.Background(), "index", func(context.Context) error {
+			runtime.Goexit()
+			return nil
+		}, WithSpanTracer(tracer))
+	}()
+	<-done
+	if len(recorder.Ended()) != 1 {
+		t.Error("expected the span to end when fn exits the goroutine")
+	}
+}
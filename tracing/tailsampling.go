@@ -0,0 +1,351 @@
+package tracing
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// KeepPolicy tells whether a trace, given its buffered spans, is exported.
+type KeepPolicy func(spans []sdktrace.ReadOnlySpan) bool
+
+// KeepErrors keeps the traces with a span whose status is error.
+func KeepErrors() KeepPolicy {
+	return func(spans []sdktrace.ReadOnlySpan) bool {
+		return slices.ContainsFunc(spans, func(span sdktrace.ReadOnlySpan) bool {
+			return span.Status().Code == codes.Error
+		})
+	}
+}
+
+// KeepSlowerThan keeps the traces lasting at least threshold, from the first
+// span start to the last span end.
+func KeepSlowerThan(threshold time.Duration) KeepPolicy {
+	return func(spans []sdktrace.ReadOnlySpan) bool {
+		var start, end time.Time
+		for i, span := range spans {
+			if i == 0 || span.StartTime().Before(start) {
+				start = span.StartTime()
+			}
+			if span.EndTime().After(end) {
+				end = span.EndTime()
+			}
+		}
+		return end.Sub(start) >= threshold
+	}
+}
+
+// KeepAttribute keeps the traces with a span carrying kv.
+func KeepAttribute(kv attribute.KeyValue) KeepPolicy {
+	return func(spans []sdktrace.ReadOnlySpan) bool {
+		return slices.ContainsFunc(spans, func(span sdktrace.ReadOnlySpan) bool {
+			return slices.Contains(span.Attributes(), kv)
+		})
+	}
+}
+
+// KeepRatio keeps ratio of the traces, chosen from the trace id like the head
+// samplers do so every service keeps the same traces.
+func KeepRatio(ratio float64) KeepPolicy {
+	sampler := sdktrace.TraceIDRatioBased(ratio)
+	return func(spans []sdktrace.ReadOnlySpan) bool {
+		if len(spans) == 0 {
+			return false
+		}
+		result := sampler.ShouldSample(sdktrace.SamplingParameters{TraceID: spans[0].SpanContext().TraceID()})
+		return result.Decision == sdktrace.RecordAndSample
+	}
+}
+
+type tailSamplingOptions struct {
+	decisionWait     time.Duration
+	maxTraces        int
+	maxSpansPerTrace int
+	meterProvider    metric.MeterProvider
+}
+
+type TailSamplingOption func(*tailSamplingOptions)
+
+// WithDecisionWait is how long a trace is buffered when its root span does not
+// end in this process, 10 seconds by default. A wait that is not positive keeps
+// the default.
+func WithDecisionWait(wait time.Duration) TailSamplingOption {
+	return func(o *tailSamplingOptions) {
+		if wait > 0 {
+			o.decisionWait = wait
+		}
+	}
+}
+
+// WithMaxTraces bounds the buffered traces, 10000 by default. When full, the
+// oldest trace is decided early with the spans it has. A limit below 1 keeps
+// the default.
+func WithMaxTraces(n int) TailSamplingOption {
+	return func(o *tailSamplingOptions) {
+		if n > 0 {
+			o.maxTraces = n
+		}
+	}
+}
+
+// WithMaxSpansPerTrace bounds the spans buffered per trace, 1000 by default. The
+// spans above it are dropped. A limit below 1 keeps the default.
+func WithMaxSpansPerTrace(n int) TailSamplingOption {
+	return func(o *tailSamplingOptions) {
+		if n > 0 {
+			o.maxSpansPerTrace = n
+		}
+	}
+}
+
+// WithTailSamplingMeterProvider overrides the global meter provider receiving
+// the tail_sampling.* metrics.
+func WithTailSamplingMeterProvider(provider metric.MeterProvider) TailSamplingOption {
+	return func(o *tailSamplingOptions) {
+		o.meterProvider = provider
+	}
+}
+
+// TailSamplingStats counts the decisions since the processor was created.
+type TailSamplingStats struct {
+	Kept         int64
+	Dropped      int64
+	Evicted      int64
+	SpansDropped int64
+	Pending      int
+}
+
+type traceBuffer struct {
+	traceID   trace.TraceID
+	spans     []sdktrace.ReadOnlySpan
+	firstSeen time.Time
+	element   *list.Element
+}
+
+type tailMetrics struct {
+	traces       metric.Int64Counter
+	evicted      metric.Int64Counter
+	spansDropped metric.Int64Counter
+}
+
+var (
+	keptDecision    = metric.WithAttributes(attribute.String("decision", "kept"))
+	droppedDecision = metric.WithAttributes(attribute.String("decision", "dropped"))
+)
+
+// TailSamplingProcessor buffers the sampled spans per trace until the local
+// root span ends or the decision wait passes, then forwards the whole trace to
+// next if any policy keeps it. Spans of a kept trace ending after the decision
+// are forwarded, those of a dropped trace are buffered again and decided with
+// their own local root, so an error in a later request of the trace is kept.
+type TailSamplingProcessor struct {
+	next     sdktrace.SpanProcessor
+	policies []KeepPolicy
+	options  tailSamplingOptions
+	metrics  *tailMetrics
+	now      func() time.Time
+
+	mutex  sync.Mutex
+	traces map[trace.TraceID]*traceBuffer
+	order  *list.List // of *traceBuffer, oldest first
+	// kept holds when the kept traces were decided.
+	kept  map[trace.TraceID]time.Time
+	stats TailSamplingStats
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewTailSamplingProcessor wraps next, usually the batch processor of the exporter:
+//
+//	processor := tracing.NewTailSamplingProcessor(sdktrace.NewBatchSpanProcessor(exporter),
+//		[]tracing.KeepPolicy{tracing.KeepErrors(), tracing.KeepSlowerThan(time.Second), tracing.KeepRatio(0.01)})
+func NewTailSamplingProcessor(next sdktrace.SpanProcessor, policies []KeepPolicy, opts ...TailSamplingOption) *TailSamplingProcessor {
+	options := tailSamplingOptions{decisionWait: 10 * time.Second, maxTraces: 10000, maxSpansPerTrace: 1000}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	p := &TailSamplingProcessor{
+		next:     next,
+		policies: policies,
+		options:  options,
+		now:      time.Now,
+		traces:   make(map[trace.TraceID]*traceBuffer),
+		order:    list.New(),
+		kept:     make(map[trace.TraceID]time.Time),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	p.metrics = newTailMetrics(options.meterProvider)
+	go p.run()
+	return p
+}
+
+func newTailMetrics(provider metric.MeterProvider) *tailMetrics {
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+	meter := provider.Meter(instrumentationName)
+	traces, err1 := meter.Int64Counter("tail_sampling.traces", metric.WithUnit("{trace}"),
+		metric.WithDescription("Traces decided by the tail sampling processor, by decision."))
+	evicted, err2 := meter.Int64Counter("tail_sampling.traces_evicted", metric.WithUnit("{trace}"),
+		metric.WithDescription("Traces decided early because the buffer was full."))
+	spansDropped, err3 := meter.Int64Counter("tail_sampling.spans_dropped", metric.WithUnit("{span}"),
+		metric.WithDescription("Spans dropped because their trace had too many spans."))
+	if err := errors.Join(err1, err2, err3); err != nil {
+		otel.Handle(err)
+		return newTailMetrics(noop.NewMeterProvider())
+	}
+	return &tailMetrics{traces: traces, evicted: evicted, spansDropped: spansDropped}
+}
+
+func (p *TailSamplingProcessor) run() {
+	defer close(p.done)
+	ticker := time.NewTicker(max(p.options.decisionWait/4, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.sweep()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *TailSamplingProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (p *TailSamplingProcessor) OnEnd(span sdktrace.ReadOnlySpan) {
+	if !span.SpanContext().IsSampled() {
+		return
+	}
+	traceID := span.SpanContext().TraceID()
+	p.mutex.Lock()
+	if _, ok := p.kept[traceID]; ok {
+		p.mutex.Unlock()
+		p.next.OnEnd(span)
+		return
+	}
+	var forward []sdktrace.ReadOnlySpan
+	buffer, ok := p.traces[traceID]
+	if !ok {
+		if len(p.traces) >= p.options.maxTraces {
+			if oldest := p.order.Front(); oldest != nil {
+				forward = p.decide(oldest.Value.(*traceBuffer))
+				p.stats.Evicted++
+				p.metrics.evicted.Add(context.Background(), 1)
+			}
+		}
+		buffer = &traceBuffer{traceID: traceID, firstSeen: p.now()}
+		buffer.element = p.order.PushBack(buffer)
+		p.traces[traceID] = buffer
+	}
+	if len(buffer.spans) < p.options.maxSpansPerTrace {
+		buffer.spans = append(buffer.spans, span)
+	} else {
+		p.stats.SpansDropped++
+		p.metrics.spansDropped.Add(context.Background(), 1)
+	}
+	if !span.Parent().IsValid() || span.Parent().IsRemote() {
+		forward = append(forward, p.decide(buffer)...)
+	}
+	p.mutex.Unlock()
+	for _, s := range forward {
+		p.next.OnEnd(s)
+	}
+}
+
+// decide must be called with the mutex held, it returns the spans to forward.
+func (p *TailSamplingProcessor) decide(buffer *traceBuffer) []sdktrace.ReadOnlySpan {
+	delete(p.traces, buffer.traceID)
+	p.order.Remove(buffer.element)
+	keep := slices.ContainsFunc(p.policies, func(policy KeepPolicy) bool { return policy(buffer.spans) })
+	if !keep {
+		p.stats.Dropped++
+		p.metrics.traces.Add(context.Background(), 1, droppedDecision)
+		return nil
+	}
+	// the decisions are bounded like the buffers, late spans of a forgotten
+	// decision start a new buffer.
+	if len(p.kept) < p.options.maxTraces {
+		p.kept[buffer.traceID] = p.now()
+	}
+	p.stats.Kept++
+	p.metrics.traces.Add(context.Background(), 1, keptDecision)
+	return buffer.spans
+}
+
+// sweep decides the traces buffered for longer than the decision wait and
+// forgets the kept decisions older than it.
+func (p *TailSamplingProcessor) sweep() {
+	p.mutex.Lock()
+	now := p.now()
+	var forward []sdktrace.ReadOnlySpan
+	for element := p.order.Front(); element != nil; {
+		buffer := element.Value.(*traceBuffer)
+		if now.Sub(buffer.firstSeen) < p.options.decisionWait {
+			break
+		}
+		element = element.Next()
+		forward = append(forward, p.decide(buffer)...)
+	}
+	for traceID, at := range p.kept {
+		if now.Sub(at) >= p.options.decisionWait {
+			delete(p.kept, traceID)
+		}
+	}
+	p.mutex.Unlock()
+	for _, span := range forward {
+		p.next.OnEnd(span)
+	}
+}
+
+// decideAll decides every buffered trace with the spans it has.
+func (p *TailSamplingProcessor) decideAll() {
+	p.mutex.Lock()
+	var forward []sdktrace.ReadOnlySpan
+	for p.order.Len() > 0 {
+		forward = append(forward, p.decide(p.order.Front().Value.(*traceBuffer))...)
+	}
+	p.mutex.Unlock()
+	for _, span := range forward {
+		p.next.OnEnd(span)
+	}
+}
+
+// Stats returns the decision counters and the number of buffered traces.
+func (p *TailSamplingProcessor) Stats() TailSamplingStats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	stats := p.stats
+	stats.Pending = len(p.traces)
+	return stats
+}
+
+// ForceFlush decides the buffered traces now and flushes next.
+func (p *TailSamplingProcessor) ForceFlush(ctx context.Context) error {
+	p.decideAll()
+	return p.next.ForceFlush(ctx)
+}
+
+// Shutdown decides the buffered traces and shuts next down.
+func (p *TailSamplingProcessor) Shutdown(ctx context.Context) error {
+	p.once.Do(func() {
+		close(p.stop)
+		<-p.done
+	})
+	p.decideAll()
+	return p.next.Shutdown(ctx)
+}
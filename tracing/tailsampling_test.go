@@ -0,0 +1,181 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTailSampling(t *testing.T, policies []KeepPolicy, opts ...TailSamplingOption) (*TailSamplingProcessor, *tracetest.SpanRecorder, trace.Tracer) {
+	recorder := tracetest.NewSpanRecorder()
+	processor := NewTailSamplingProcessor(recorder, policies, opts...)
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithSpanProcessor(processor))
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+	return processor, recorder, provider.Tracer("test")
+}
+
+func TestTailSamplingKeepsErrorsAndSlowTraces(t *testing.T) {
+	processor, recorder, tracer := newTailSampling(t, []KeepPolicy{KeepErrors(), KeepSlowerThan(time.Second), KeepRatio(0)})
+	start := time.Now()
+
+	ctx, root := tracer.Start(context.Background(), "CreateOrder")
+	_, child := tracer.Start(ctx, "dynamodb.PutItem")
+	child.SetStatus(codes.Error, "throttled")
+	child.End()
+	root.End()
+
+	ctx, root = tracer.Start(context.Background(), "GetOrder")
+	_, child = tracer.Start(ctx, "dynamodb.GetItem")
+	child.End()
+	root.End()
+
+	_, root = tracer.Start(context.Background(), "ListOrders", trace.WithTimestamp(start))
+	root.End(trace.WithTimestamp(start.Add(2 * time.Second)))
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+	expected := []string{"dynamodb.PutItem", "CreateOrder", "ListOrders"}
+	if len(names) != len(expected) || names[0] != expected[0] || names[1] != expected[1] || names[2] != expected[2] {
+		t.Errorf("expected %v to be exported, got %v", expected, names)
+	}
+	if stats := processor.Stats(); stats.Kept != 2 || stats.Dropped != 1 || stats.Pending != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestTailSamplingDecisionWait(t *testing.T) {
+	processor, recorder, tracer := newTailSampling(t, []KeepPolicy{KeepAttribute(attribute.Bool("debug", true))}, WithDecisionWait(time.Hour))
+	now := time.Now()
+	processor.now = func() time.Time { return now }
+
+	// the root span lives in the caller, only the children end here.
+	parent := trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1}, TraceFlags: trace.FlagsSampled}
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(parent))
+	ctx, handler := tracer.Start(ctx, "handler")
+	_, child := tracer.Start(ctx, "cache.Get", trace.WithAttributes(attribute.Bool("debug", true)))
+	child.End()
+
+	processor.sweep()
+	if len(recorder.Ended()) != 0 || processor.Stats().Pending != 1 {
+		t.Fatalf("expected the trace to be buffered, got %d spans", len(recorder.Ended()))
+	}
+	now = now.Add(time.Hour)
+	processor.sweep()
+	if len(recorder.Ended()) != 1 {
+		t.Fatalf("expected the trace to be decided after the wait, got %d spans", len(recorder.Ended()))
+	}
+	// spans ending after the decision follow it.
+	handler.End()
+	if len(recorder.Ended()) != 2 {
+		t.Errorf("expected the late span to be exported, got %d spans", len(recorder.Ended()))
+	}
+}
+
+func TestTailSamplingLocalRoots(t *testing.T) {
+	processor, recorder, tracer := newTailSampling(t, []KeepPolicy{KeepErrors()})
+
+	// two requests of the same trace reach this service, the second one fails.
+	parent := trace.SpanContextConfig{TraceID: trace.TraceID{2}, SpanID: trace.SpanID{1}, TraceFlags: trace.FlagsSampled}
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(parent).WithRemote(true))
+	_, first := tracer.Start(ctx, "GetOrder")
+	first.End()
+	ctx, second := tracer.Start(ctx, "UpdateOrder")
+	_, child := tracer.Start(ctx, "dynamodb.UpdateItem")
+	child.SetStatus(codes.Error, "conditional check failed")
+	child.End()
+	second.End()
+
+	ended := recorder.Ended()
+	if len(ended) != 2 || ended[0].Name() != "dynamodb.UpdateItem" || ended[1].Name() != "UpdateOrder" {
+		t.Fatalf("expected the failed request to be exported, got %d spans", len(ended))
+	}
+	if stats := processor.Stats(); stats.Kept != 1 || stats.Dropped != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestTailSamplingOptionDefaults(t *testing.T) {
+	processor, _, _ := newTailSampling(t, nil, WithMaxTraces(0), WithMaxSpansPerTrace(-1), WithDecisionWait(0))
+	if options := processor.options; options.maxTraces != 10000 || options.maxSpansPerTrace != 1000 || options.decisionWait != 10*time.Second {
+		t.Errorf("expected invalid limits to keep the defaults, got %+v", options)
+	}
+}
+
+func TestTailSamplingLimits(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	processor, recorder, tracer := newTailSampling(t, []KeepPolicy{KeepRatio(1)},
+		WithMaxTraces(1), WithMaxSpansPerTrace(2), WithTailSamplingMeterProvider(provider))
+
+	first, _ := tracer.Start(context.Background(), "first")
+	for range 3 {
+		_, span := tracer.Start(first, "step")
+		span.End()
+	}
+	second, _ := tracer.Start(context.Background(), "second")
+	_, span := tracer.Start(second, "step")
+	span.End()
+
+	if len(recorder.Ended()) != 2 {
+		t.Errorf("expected the first trace to be evicted with 2 spans, got %d", len(recorder.Ended()))
+	}
+	if err := processor.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if stats := processor.Stats(); stats.Kept != 2 || stats.Evicted != 1 || stats.SpansDropped != 1 || stats.Pending != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatal(err)
+	}
+	totals := make(map[string]int64)
+	for _, m := range data.ScopeMetrics[0].Metrics {
+		for _, point := range m.Data.(metricdata.Sum[int64]).DataPoints {
+			totals[m.Name] += point.Value
+		}
+	}
+	if totals["tail_sampling.traces"] != 2 || totals["tail_sampling.traces_evicted"] != 1 || totals["tail_sampling.spans_dropped"] != 1 {
+		t.Errorf("unexpected totals %v", totals)
+	}
+}
+
+func TestWithTailSampling(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown, err := InitGlobalTracer(exporter, resource.Empty(), WithTailSampling([]KeepPolicy{KeepErrors()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, err := range []error{nil, errors.New("boom")} {
+		_, span := otel.Tracer("test").Start(context.Background(), "CreateOrder")
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+	defer shutdown(context.Background())
+	if err := otel.GetTracerProvider().(*sdktrace.TracerProvider).ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if spans := exporter.GetSpans(); len(spans) != 1 || spans[0].Status.Code != codes.Error {
+		t.Errorf("expected only the error trace to be exported, got %d spans", len(spans))
+	}
+}
@@ -20,15 +20,78 @@ func NewSpan(tracer trace.Tracer, ctx context.Context, spanName string) (context
 	return tracer.Start(ctx, spanName)
 }
 
+type providerOptions struct {
+	sampler      sdktrace.Sampler
+	batchOptions []sdktrace.BatchSpanProcessorOption
+	tailPolicies []KeepPolicy
+	tailOptions  []TailSamplingOption
+	baggageKeys  []string
+}
+
+type ProviderOption func(*providerOptions)
+
+// WithSampler replaces the default AlwaysSample, pass a *Sampler to change the
+// sampling at runtime:
+//
+//	sampler, _ := tracing.NewSampler(0.1, tracing.NeverSample(tracing.HealthCheckPattern))
+//	shutdown, err := tracing.InitGlobalTracer(exporter, res, tracing.WithSampler(sampler))
+//	...
+//	sampler.SetRatio(0.5)
+func WithSampler(sampler sdktrace.Sampler) ProviderOption {
+	return func(o *providerOptions) {
+		o.sampler = sampler
+	}
+}
+
+// WithBatchOptions tunes the batch span processor sending spans to the exporter.
+func WithBatchOptions(opts ...sdktrace.BatchSpanProcessorOption) ProviderOption {
+	return func(o *providerOptions) {
+		o.batchOptions = append(o.batchOptions, opts...)
+	}
+}
+
+// WithTailSampling puts a TailSamplingProcessor in front of the exporter batcher,
+// head sampling still applies first.
+func WithTailSampling(policies []KeepPolicy, opts ...TailSamplingOption) ProviderOption {
+	return func(o *providerOptions) {
+		o.tailPolicies = policies
+		o.tailOptions = opts
+	}
+}
+
+// WithBaggageAttributes copies the baggage members under keys, DefaultBaggageKeys
+// when none is given, to the attributes of every span.
+func WithBaggageAttributes(keys ...string) ProviderOption {
+	return func(o *providerOptions) {
+		if len(keys) == 0 {
+			keys = DefaultBaggageKeys
+		}
+		o.baggageKeys = keys
+	}
+}
+
 // InitGlobalTracer wires up the global OTEL provider with the given dependencies.
 // Use this if you want to bring your own Exporter (Jaeger/Stdout) or custom Resource.
-func InitGlobalTracer(exporter sdktrace.SpanExporter, res *resource.Resource) (func(context.Context) error, error) {
+func InitGlobalTracer(exporter sdktrace.SpanExporter, res *resource.Resource, opts ...ProviderOption) (func(context.Context) error, error) {
+	options := providerOptions{sampler: sdktrace.AlwaysSample()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	// 1. Create the TracerProvider with the injected Exporter and Resource
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter),
+	var processor sdktrace.SpanProcessor = sdktrace.NewBatchSpanProcessor(exporter, options.batchOptions...)
+	if options.tailPolicies != nil {
+		processor = NewTailSamplingProcessor(processor, options.tailPolicies, options.tailOptions...)
+	}
+	providerOptions := []sdktrace.TracerProviderOption{
+		sdktrace.WithSpanProcessor(processor),
 		sdktrace.WithResource(res),
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-	)
+		sdktrace.WithSampler(options.sampler),
+	}
+	if options.baggageKeys != nil {
+		providerOptions = append(providerOptions, sdktrace.WithSpanProcessor(NewBaggageSpanProcessor(options.baggageKeys...)))
+	}
+	tp := sdktrace.NewTracerProvider(providerOptions...)
 
 	// 2. Set Globals
 	otel.SetTracerProvider(tp)
@@ -40,12 +103,22 @@ func InitGlobalTracer(exporter sdktrace.SpanExporter, res *resource.Resource) (f
 	return tp.Shutdown, nil
 }
 
-// DefaultGlobalTracer sets up the tracer with Zipkin and default resource detection.
+// DefaultGlobalTracer sets up the tracer with the given exporter and default resource detection.
 // It simplifies identifying the service via a string name.
-func DefaultGlobalTracer(serviceName string, exporter sdktrace.SpanExporter) (func(context.Context) error, error) {
+func DefaultGlobalTracer(serviceName string, exporter sdktrace.SpanExporter, opts ...ProviderOption) (func(context.Context) error, error) {
+	// 1. Default Resource: Service Name + OS/Container info
+	res, err := newResource(context.Background(), serviceName)
+	if err != nil {
+		return nil, err
+	}
 
-	// 2. Default Resource: Service Name + OS/Container info
-	res, err := resource.New(context.Background(),
+	// 2. Delegate to Core
+	return InitGlobalTracer(exporter, res, opts...)
+}
+
+// newResource is the service name with the OS/container/process info.
+func newResource(ctx context.Context, serviceName string) (*resource.Resource, error) {
+	res, err := resource.New(ctx,
 		resource.WithAttributes(semconv.ServiceNameKey.String(serviceName)),
 		resource.WithFromEnv(),
 		resource.WithProcess(),
@@ -56,9 +129,7 @@ func DefaultGlobalTracer(serviceName string, exporter sdktrace.SpanExporter) (fu
 	if err != nil {
 		return nil, fmt.Errorf("failed to create resource: %w", err)
 	}
-
-	// 3. Delegate to Core
-	return InitGlobalTracer(exporter, res)
+	return res, nil
 }
 
 type NoopExporter struct{}
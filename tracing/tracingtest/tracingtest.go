@@ -0,0 +1,235 @@
+// Package tracingtest records the spans created during a test so it can assert
+// on their names, structure, attributes, events and status.
+package tracingtest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Spans is a list of ended spans that can be narrowed down with filters.
+type Spans []tracetest.SpanStub
+
+// Recorder holds every span ended since New or the last Reset.
+type Recorder struct {
+	t        testing.TB
+	exporter *tracetest.InMemoryExporter
+	provider *sdktrace.TracerProvider
+}
+
+// New installs a provider sampling and recording every span as the global
+// provider, with the W3C propagators, until the test ends.
+func New(t testing.TB) *Recorder {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exporter),
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+	)
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	t.Cleanup(func() {
+		// t.Context is already cancelled when the cleanup functions run.
+		provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return &Recorder{t: t, exporter: exporter, provider: provider}
+}
+
+// Provider is the recording provider, for code taking a provider explicitly.
+func (r *Recorder) Provider() *sdktrace.TracerProvider {
+	return r.provider
+}
+
+// Spans returns the ended spans in the order they ended.
+func (r *Recorder) Spans() Spans {
+	return Spans(r.exporter.GetSpans())
+}
+
+// Reset removes every recorded span.
+func (r *Recorder) Reset() {
+	r.exporter.Reset()
+}
+
+func (s Spans) filter(keep func(tracetest.SpanStub) bool) Spans {
+	var filtered Spans
+	for _, span := range s {
+		if keep(span) {
+			filtered = append(filtered, span)
+		}
+	}
+	return filtered
+}
+
+// Name keeps the spans named name.
+func (s Spans) Name(name string) Spans {
+	return s.filter(func(span tracetest.SpanStub) bool { return span.Name == name })
+}
+
+// Kind keeps the spans of kind.
+func (s Spans) Kind(kind trace.SpanKind) Spans {
+	return s.filter(func(span tracetest.SpanStub) bool { return span.SpanKind == kind })
+}
+
+// Attribute keeps the spans carrying kv.
+func (s Spans) Attribute(kv attribute.KeyValue) Spans {
+	return s.filter(func(span tracetest.SpanStub) bool { return slices.Contains(span.Attributes, kv) })
+}
+
+// ChildrenOf keeps the direct children of parent.
+func (s Spans) ChildrenOf(parent tracetest.SpanStub) Spans {
+	return s.filter(func(span tracetest.SpanStub) bool {
+		return span.Parent.SpanID() == parent.SpanContext.SpanID() && span.Parent.TraceID() == parent.SpanContext.TraceID()
+	})
+}
+
+// Names returns the span names in ending order, handy in failure output.
+func (s Spans) Names() []string {
+	names := make([]string, len(s))
+	for i, span := range s {
+		names[i] = span.Name
+	}
+	return names
+}
+
+// FindSpan returns the first span named name and fails the test when there is none.
+func (r *Recorder) FindSpan(name string) (tracetest.SpanStub, bool) {
+	r.t.Helper()
+	spans := r.Spans().Name(name)
+	if len(spans) == 0 {
+		r.t.Errorf("expected a span %q, recorded:\n%s", name, r.Tree())
+		return tracetest.SpanStub{}, false
+	}
+	return spans[0], true
+}
+
+// AssertChild fails the test unless a span named child is a direct child of a span named parent.
+func (r *Recorder) AssertChild(parent string, child string) {
+	r.t.Helper()
+	spans := r.Spans()
+	for _, p := range spans.Name(parent) {
+		if len(spans.ChildrenOf(p).Name(child)) > 0 {
+			return
+		}
+	}
+	r.t.Errorf("expected %q to be a child of %q, recorded:\n%s", child, parent, r.Tree())
+}
+
+// AssertAttributes fails the test unless the span named name carries every kv.
+func (r *Recorder) AssertAttributes(name string, kvs ...attribute.KeyValue) {
+	r.t.Helper()
+	span, ok := r.FindSpan(name)
+	if !ok {
+		return
+	}
+	for _, kv := range kvs {
+		if !slices.Contains(span.Attributes, kv) {
+			r.t.Errorf("span %q: expected attribute %s=%s, recorded:\n%s", name, kv.Key, kv.Value.Emit(), r.Tree())
+		}
+	}
+}
+
+// AssertEvent fails the test unless the span named name has an event named event
+// carrying every kv.
+func (r *Recorder) AssertEvent(name string, event string, kvs ...attribute.KeyValue) {
+	r.t.Helper()
+	span, ok := r.FindSpan(name)
+	if !ok {
+		return
+	}
+	for _, e := range span.Events {
+		if e.Name != event {
+			continue
+		}
+		matched := true
+		for _, kv := range kvs {
+			matched = matched && slices.Contains(e.Attributes, kv)
+		}
+		if matched {
+			return
+		}
+	}
+	r.t.Errorf("span %q: expected an event %q with %v, recorded:\n%s", name, event, kvs, r.Tree())
+}
+
+// AssertStatus fails the test unless the span named name has the status code.
+func (r *Recorder) AssertStatus(name string, code codes.Code) {
+	r.t.Helper()
+	span, ok := r.FindSpan(name)
+	if !ok {
+		return
+	}
+	if span.Status.Code != code {
+		r.t.Errorf("span %q: expected status %s, got %s, recorded:\n%s", name, code, span.Status.Code, r.Tree())
+	}
+}
+
+// Tree renders the recorded spans per trace, children under their parent in
+// starting order.
+func (r *Recorder) Tree() string {
+	spans := r.Spans()
+	if len(spans) == 0 {
+		return "  (no spans)\n"
+	}
+	slices.SortStableFunc(spans, func(a, b tracetest.SpanStub) int { return a.StartTime.Compare(b.StartTime) })
+	recorded := make(map[trace.SpanID]bool)
+	for _, span := range spans {
+		recorded[span.SpanContext.SpanID()] = true
+	}
+	var builder strings.Builder
+	var traces []trace.TraceID
+	for _, span := range spans {
+		if !slices.Contains(traces, span.SpanContext.TraceID()) {
+			traces = append(traces, span.SpanContext.TraceID())
+		}
+	}
+	var write func(span tracetest.SpanStub, depth int)
+	write = func(span tracetest.SpanStub, depth int) {
+		builder.WriteString(strings.Repeat("  ", depth+1))
+		builder.WriteString(describe(span))
+		builder.WriteString("\n")
+		for _, child := range spans.ChildrenOf(span) {
+			write(child, depth+1)
+		}
+	}
+	for _, traceID := range traces {
+		fmt.Fprintf(&builder, "trace %s\n", traceID)
+		for _, span := range spans {
+			if span.SpanContext.TraceID() == traceID && !recorded[span.Parent.SpanID()] {
+				write(span, 0)
+			}
+		}
+	}
+	return builder.String()
+}
+
+func describe(span tracetest.SpanStub) string {
+	state := []string{span.SpanKind.String()}
+	if span.Status.Code != codes.Unset {
+		status := strings.ToLower(span.Status.Code.String())
+		if span.Status.Description != "" {
+			status += ": " + span.Status.Description
+		}
+		state = append(state, status)
+	}
+	description := fmt.Sprintf("%s (%s)", span.Name, strings.Join(state, ", "))
+	for _, kv := range span.Attributes {
+		description += fmt.Sprintf(" %s=%s", kv.Key, kv.Value.Emit())
+	}
+	for _, event := range span.Events {
+		description += fmt.Sprintf(" [%s]", event.Name)
+	}
+	return description
+}
@@ -0,0 +1,89 @@
+package tracingtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/phuthien0308/ordering-base/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeT records failures instead of failing the real test.
+type fakeT struct {
+	testing.TB
+	failures []string
+	cleanups []func()
+}
+
+func (f *fakeT) Helper()                  {}
+func (f *fakeT) Context() context.Context { return context.Background() }
+func (f *fakeT) Cleanup(fn func())        { f.cleanups = append(f.cleanups, fn) }
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func createProduct(ctx context.Context) {
+	tracer := tracing.NewTracer("productservice")
+	ctx, span := tracer.Start(ctx, "CreateProduct", trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("product.sku", "A-1")))
+	defer span.End()
+
+	_, insert := tracer.Start(ctx, "dynamodb.PutItem", trace.WithSpanKind(trace.SpanKindClient))
+	insert.AddEvent("retry", trace.WithAttributes(attribute.Int("attempt", 2)))
+	insert.RecordError(errors.New("throttled"))
+	insert.SetStatus(codes.Error, "throttled")
+	insert.End()
+}
+
+func TestRecorder(t *testing.T) {
+	recorder := New(t)
+	createProduct(context.Background())
+
+	if spans := recorder.Spans(); len(spans) != 2 || len(spans.Kind(trace.SpanKindClient)) != 1 ||
+		len(spans.Attribute(attribute.String("product.sku", "A-1"))) != 1 {
+		t.Errorf("unexpected spans %v", spans.Names())
+	}
+	recorder.AssertChild("CreateProduct", "dynamodb.PutItem")
+	recorder.AssertAttributes("CreateProduct", attribute.String("product.sku", "A-1"))
+	recorder.AssertEvent("dynamodb.PutItem", "retry", attribute.Int("attempt", 2))
+	recorder.AssertEvent("dynamodb.PutItem", "exception")
+	recorder.AssertStatus("dynamodb.PutItem", codes.Error)
+	recorder.AssertStatus("CreateProduct", codes.Unset)
+
+	tree := recorder.Tree()
+	expected := "  CreateProduct (server) product.sku=A-1\n    dynamodb.PutItem (client, error: throttled) [retry] [exception]\n"
+	if !strings.HasPrefix(tree, "trace ") || !strings.HasSuffix(tree, expected) {
+		t.Errorf("unexpected tree:\n%s", tree)
+	}
+
+	recorder.Reset()
+	if len(recorder.Spans()) != 0 {
+		t.Error("expected no spans after Reset")
+	}
+}
+
+func TestAssertionsFail(t *testing.T) {
+	fake := &fakeT{}
+	recorder := New(fake)
+	createProduct(context.Background())
+
+	recorder.AssertChild("dynamodb.PutItem", "CreateProduct")
+	recorder.AssertAttributes("CreateProduct", attribute.String("product.sku", "B-2"))
+	recorder.AssertEvent("CreateProduct", "retry")
+	recorder.AssertStatus("CreateProduct", codes.Ok)
+	recorder.AssertStatus("UpdateProduct", codes.Ok)
+	for _, cleanup := range fake.cleanups {
+		cleanup()
+	}
+	if len(fake.failures) != 5 {
+		t.Fatalf("expected 5 failures, got %q", fake.failures)
+	}
+	if !strings.Contains(fake.failures[0], "dynamodb.PutItem (client, error: throttled)") {
+		t.Errorf("expected the failure to print the trace tree, got %q", fake.failures[0])
+	}
+}